@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoDebt = errors.New("no outstanding debt")
+
+// debtBalanceSQL sums a person's entries into what they owe the user; negative when the user owes them.
+const debtBalanceSQL = "COALESCE(SUM(CASE WHEN kind IN ('lent', 'repaid_by_me') THEN amount ELSE -amount END), 0)"
+
+// RecordLoan records money the user lent to or borrowed from a person. With a due date, a debt
+// reminder is created for the reminder processor to send on that day.
+func RecordLoan(userID string, loan utils.LoanInput) (*models.DebtEntry, error) {
+	if loan.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	entry := models.DebtEntry{
+		UserID:  userID,
+		Person:  loan.Person,
+		Kind:    models.DebtBorrowed,
+		Amount:  loan.Amount,
+		DueDate: loan.DueDate,
+	}
+	if loan.Lent {
+		entry.Kind = models.DebtLent
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if loan.DueDate != nil {
+			now := time.Now()
+			reminder := models.RemindersLog{
+				Amount:          loan.Amount,
+				Recipient:       loan.Person,
+				DueDate:         *loan.DueDate,
+				PaymentMethod:   "Cash",
+				Status:          models.ReminderPending,
+				StatusChangedAt: &now,
+				UserID:          userID,
+				ReminderType:    "debt",
+				Frequency:       "once",
+				DueTimeSet:      loan.HasTime,
+			}
+			if err := tx.Create(&reminder).Error; err != nil {
+				return err
+			}
+			entry.ReminderID = &reminder.ID
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RecordRepayment records a person paying the user back (toMe) or the user paying a person back,
+// whether the money was owed from a loan or a split expense. The amount can't be more than is
+// owed in that direction. Once the balance is cleared, the person's debt reminders are marked
+// paid. It returns the balance after the repayment.
+func RecordRepayment(userID string, person string, amount float64, toMe bool, paidAt time.Time) (*utils.PersonDebt, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	debt, err := GetDebtBalance(userID, person)
+	if err != nil {
+		return nil, err
+	}
+	owed := math.Round(debt.Balance*100) / 100
+	kind := models.DebtRepaidToMe
+	if !toMe {
+		owed = -owed
+		kind = models.DebtRepaidByMe
+	}
+	if owed <= 0 {
+		if toMe {
+			return nil, fmt.Errorf("%w: %s doesn't owe you anything", ErrNoDebt, debt.Name)
+		}
+		return nil, fmt.Errorf("%w: you don't owe %s anything", ErrNoDebt, debt.Name)
+	}
+	if amount > owed {
+		return nil, fmt.Errorf("that's more than the ₱%.2f outstanding with %s", owed, debt.Name)
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		entry := models.DebtEntry{UserID: userID, Person: debt.Name, Kind: kind, Amount: amount}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		if owed-amount >= 0.005 {
+			return nil
+		}
+		return closeDebtReminders(tx, userID, debt.Name, paidAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if toMe {
+		debt.Balance -= amount
+	} else {
+		debt.Balance += amount
+	}
+	return debt, nil
+}
+
+// closeDebtReminders marks the active debt reminders for a person as paid.
+func closeDebtReminders(tx *gorm.DB, userID string, person string, at time.Time) error {
+	var reminderIDs []uint
+	err := tx.Model(&models.DebtEntry{}).
+		Where("user_id = ? AND LOWER(person) = LOWER(?) AND reminder_id IS NOT NULL", userID, person).
+		Pluck("reminder_id", &reminderIDs).Error
+	if err != nil || len(reminderIDs) == 0 {
+		return err
+	}
+
+	var reminders []models.RemindersLog
+	if err := tx.Where("id IN ? AND status IN ?", reminderIDs, activeReminderStatuses).Find(&reminders).Error; err != nil {
+		return err
+	}
+	for i := range reminders {
+		if err := transitionReminder(tx, &reminders[i], models.ReminderPaid, at, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDebtBalance returns the running balance between the user and a person, matched
+// case-insensitively, counting loans, repayments and split expense shares alike. A person with
+// no entries has a zero balance under the given name.
+func GetDebtBalance(userID string, person string) (*utils.PersonDebt, error) {
+	debts, err := personBalances(database.DB, userID, strings.TrimSpace(person))
+	if err != nil {
+		return nil, err
+	}
+	if len(debts) == 0 {
+		return &utils.PersonDebt{Name: strings.TrimSpace(person)}, nil
+	}
+	return &debts[0], nil
+}
+
+// GetDebtBalances returns the running balance with everyone the user has loans or split
+// expenses with, by name.
+func GetDebtBalances(userID string) ([]utils.PersonDebt, error) {
+	return personBalances(database.DB, userID, "")
+}
+
+// personBalances sums what each person owes the user across loans and repayments, split
+// expense shares and older split settle-ups, merging names case-insensitively. When person is
+// set, only that person is returned.
+func personBalances(db *gorm.DB, userID string, person string) ([]utils.PersonDebt, error) {
+	totalsByPerson := func(model interface{}, total string) *gorm.DB {
+		query := db.Model(model).
+			Select("MAX(person) AS name, "+total+" AS balance").
+			Where("user_id = ?", userID).
+			Group("LOWER(person)")
+		if person != "" {
+			query = query.Where("LOWER(person) = LOWER(?)", person)
+		}
+		return query
+	}
+
+	var entries, shares, settlements []utils.PersonDebt
+	if err := totalsByPerson(&models.DebtEntry{}, debtBalanceSQL).Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	if err := totalsByPerson(&models.SplitShare{}, "SUM(amount)").Scan(&shares).Error; err != nil {
+		return nil, err
+	}
+	if err := totalsByPerson(&models.SplitSettlement{}, "-SUM(amount)").Scan(&settlements).Error; err != nil {
+		return nil, err
+	}
+
+	// Loans are listed first, so a person keeps the name their debts were recorded under
+	names := make(map[string]string)
+	totals := make(map[string]float64)
+	var keys []string
+	for _, rows := range [][]utils.PersonDebt{entries, shares, settlements} {
+		for _, row := range rows {
+			key := strings.ToLower(row.Name)
+			if _, seen := names[key]; !seen {
+				names[key] = row.Name
+				keys = append(keys, key)
+			}
+			totals[key] += row.Balance
+		}
+	}
+	sort.Strings(keys)
+
+	debts := make([]utils.PersonDebt, 0, len(keys))
+	for _, key := range keys {
+		debts = append(debts, utils.PersonDebt{Name: names[key], Balance: totals[key]})
+	}
+	return debts, nil
+}
+
+// GetDebtHistory returns the loans and repayments between the user and a person, oldest first.
+func GetDebtHistory(userID string, person string) ([]models.DebtEntry, error) {
+	var entries []models.DebtEntry
+	result := database.DB.
+		Where("user_id = ? AND LOWER(person) = LOWER(?)", userID, strings.TrimSpace(person)).
+		Order("created_at asc").
+		Find(&entries)
+	return entries, result.Error
+}
+
+// GetDebtForReminder returns the loan a debt reminder was created for and the current balance
+// with that person.
+func GetDebtForReminder(reminder models.RemindersLog) (*models.DebtEntry, *utils.PersonDebt, error) {
+	var entry models.DebtEntry
+	if err := database.DB.Where("reminder_id = ?", reminder.ID).First(&entry).Error; err != nil {
+		return nil, nil, err
+	}
+	debt, err := GetDebtBalance(entry.UserID, entry.Person)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &entry, debt, nil
+}
@@ -2,12 +2,21 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"quickyexpensetracker/database"
 	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
-func SaveExpense(amount float64, category string, psid string) error {
+// baselineWindowDays is how far back GetCategoryBaseline looks when building a category baseline.
+const baselineWindowDays = 90
+
+func SaveExpense(amount float64, category string, psid string) (*models.ExpensesLog, error) {
 	expense := models.ExpensesLog{
 		Amount:   amount,
 		Category: category,
@@ -15,25 +24,86 @@ func SaveExpense(amount float64, category string, psid string) error {
 	}
 
 	result := database.DB.Create(&expense)
+	if result.Error != nil {
+		return nil, result.Error
+	}
 
-	return result.Error
+	return &expense, nil
 }
 
-func GetExpensesByUserAndRange(userID string, rangeType string) ([]models.ExpensesLog, error) {
-	var expenses []models.ExpensesLog
-	var startTime time.Time
+// DeleteExpenseByID removes a single expense, scoped to its owner, along with its receipt photos.
+func DeleteExpenseByID(userID string, expenseID string) error {
+	expenseIDUint, err := strconv.ParseUint(expenseID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error converting expenseID to uint: %w", err)
+	}
+
+	var receipts []models.ExpenseReceipt
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", expenseIDUint, userID).Delete(&models.ExpensesLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("expense %s not found for user", expenseID)
+		}
+
+		// Unlink any reminder payment or split that was logged as this expense
+		if err := tx.Model(&models.ReminderOccurrence{}).Where("expense_id = ?", expenseIDUint).Update("expense_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SplitExpense{}).Where("expense_id = ?", expenseIDUint).Update("expense_id", nil).Error; err != nil {
+			return err
+		}
+		receipts, err = deleteExpenseReceipts(tx, "expense_id = ?", expenseIDUint)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteReceiptPhotos(userID, receipts)
+	return nil
+}
+
+// GetCategoryBaseline computes the median and spread of a user's recent expenses in a category.
+// Categories are matched case-insensitively.
+func GetCategoryBaseline(userID string, category string) (utils.CategoryBaseline, error) {
+	var amounts []float64
+	since := time.Now().AddDate(0, 0, -baselineWindowDays)
+
+	result := database.DB.Model(&models.ExpensesLog{}).
+		Where("user_id = ? AND LOWER(category) = LOWER(?) AND created_at >= ?", userID, category, since).
+		Pluck("amount", &amounts)
+	if result.Error != nil {
+		return utils.CategoryBaseline{}, result.Error
+	}
+
+	return utils.ComputeBaseline(amounts), nil
+}
 
+// rangeStartTime returns the start of a report range relative to now.
+func rangeStartTime(rangeType string) (time.Time, error) {
 	now := time.Now()
 
 	switch rangeType {
 	case "day":
-		startTime = now.AddDate(0, 0, -1) // last 24 hours
+		return now.AddDate(0, 0, -1), nil // last 24 hours
 	case "week":
-		startTime = now.AddDate(0, 0, -7) // last 7 days
+		return now.AddDate(0, 0, -7), nil // last 7 days
 	case "month":
-		startTime = now.AddDate(0, -1, 0) // last 1 month
+		return now.AddDate(0, -1, 0), nil // last 1 month
 	default:
-		return nil, errors.New("invalid range type: choose 'day', 'week', or 'month'")
+		return time.Time{}, errors.New("invalid range type: choose 'day', 'week', or 'month'")
+	}
+}
+
+func GetExpensesByUserAndRange(userID string, rangeType string) ([]models.ExpensesLog, error) {
+	var expenses []models.ExpensesLog
+
+	startTime, err := rangeStartTime(rangeType)
+	if err != nil {
+		return nil, err
 	}
 
 	result := database.DB.
@@ -44,9 +114,51 @@ func GetExpensesByUserAndRange(userID string, rangeType string) ([]models.Expens
 	return expenses, result.Error
 }
 
+// GetExpensesByCategoryAndRange returns one page of a user's expenses in a category
+// (matched case-insensitively) within a report range, newest first, along with the
+// total number of matching expenses.
+func GetExpensesByCategoryAndRange(userID string, category string, rangeType string, offset int, limit int) ([]models.ExpensesLog, int64, error) {
+	var expenses []models.ExpensesLog
+	var count int64
+
+	startTime, err := rangeStartTime(rangeType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := database.DB.Model(&models.ExpensesLog{}).
+		Where("user_id = ? AND LOWER(category) = LOWER(?) AND created_at >= ?", userID, category, startTime)
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.
+		Order("created_at desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&expenses)
+
+	return expenses, count, result.Error
+}
+
+// DeleteExpensesByUser removes all of a user's expenses along with their receipt photos.
 func DeleteExpensesByUser(userID string) error {
-	result := database.DB.Where("user_id = ?", userID).Delete(&models.ExpensesLog{})
-	return result.Error
+	var receipts []models.ExpenseReceipt
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ExpensesLog{}).Error; err != nil {
+			return err
+		}
+		var err error
+		receipts, err = deleteExpenseReceipts(tx, "user_id = ?", userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteReceiptPhotos(userID, receipts)
+	return nil
 }
 
 // GetExpensesForPeriod retrieves expenses for a user within a specific date range and calculates the total amount.
@@ -80,3 +192,48 @@ func GetExpensesForPeriod(userID string, periodStartDate time.Time, periodEndDat
 
 	return expenses, totalAmount, nil
 }
+
+// ExpenseSearch filters a user's expense history. Zero values leave a filter unset.
+type ExpenseSearch struct {
+	Text      string
+	MinAmount *float64
+	MaxAmount *float64
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetRecentExpenses returns one page of a user's expenses, newest first, with the total count.
+func GetRecentExpenses(userID string, offset int, limit int) ([]models.ExpensesLog, int64, error) {
+	return SearchExpenses(userID, ExpenseSearch{}, offset, limit)
+}
+
+// SearchExpenses returns one page of a user's expenses matching the search, newest first,
+// with the total number of matches. Text matches anywhere in the category, ignoring case.
+func SearchExpenses(userID string, search ExpenseSearch, offset int, limit int) ([]models.ExpensesLog, int64, error) {
+	var expenses []models.ExpensesLog
+	var count int64
+
+	query := database.DB.Model(&models.ExpensesLog{}).Where("user_id = ?", userID)
+	if search.Text != "" {
+		escaped := likeEscaper.Replace(strings.ToLower(search.Text))
+		query = query.Where("LOWER(category) LIKE ?", "%"+escaped+"%")
+	}
+	if search.MinAmount != nil {
+		query = query.Where("amount >= ?", *search.MinAmount)
+	}
+	if search.MaxAmount != nil {
+		query = query.Where("amount <= ?", *search.MaxAmount)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.
+		Order("created_at desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&expenses)
+
+	return expenses, count, result.Error
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrGoalNotFound = errors.New("savings goal not found")
+
+func SaveGoal(userID string, name string, targetAmount float64, targetDate *time.Time) (*models.SavingsGoal, error) {
+	if targetAmount <= 0 {
+		return nil, fmt.Errorf("the target amount must be greater than zero")
+	}
+
+	existing, err := GetGoalByName(userID, name)
+	if err != nil && !errors.Is(err, ErrGoalNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("you already have a goal named %s", existing.Name)
+	}
+
+	goal := models.SavingsGoal{
+		UserID:       userID,
+		Name:         name,
+		TargetAmount: targetAmount,
+		TargetDate:   targetDate,
+	}
+
+	result := database.DB.Create(&goal)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &goal, nil
+}
+
+// GetGoalByName looks up a user's goal by name, ignoring case.
+func GetGoalByName(userID string, name string) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+	result := database.DB.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&goal)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrGoalNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &goal, nil
+}
+
+func GetGoals(userID string) ([]models.SavingsGoal, error) {
+	var goals []models.SavingsGoal
+	result := database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&goals)
+	return goals, result.Error
+}
+
+// AddGoalContribution records money set aside for a goal and updates the goal's saved total.
+func AddGoalContribution(userID string, goalName string, amount float64) (*models.SavingsGoal, error) {
+	goal, err := GetGoalByName(userID, goalName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		contribution := models.GoalContribution{
+			GoalID: goal.ID,
+			UserID: userID,
+			Amount: amount,
+		}
+		if err := tx.Create(&contribution).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(goal).Update("saved_amount", gorm.Expr("saved_amount + ?", amount)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	goal.SavedAmount += amount
+	return goal, nil
+}
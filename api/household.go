@@ -0,0 +1,215 @@
+package api
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoHousehold        = errors.New("not in a household")
+	ErrAlreadyInHousehold = errors.New("already in a household")
+	ErrInvalidInviteCode  = errors.New("invalid invite code")
+	ErrExpenseNotFound    = errors.New("expense not found")
+)
+
+// inviteCodeAlphabet leaves out characters that are easy to mix up when typed, like 0 and O.
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const inviteCodeLength = 6
+
+func newInviteCode() (string, error) {
+	code := make([]byte, inviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(inviteCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CreateHousehold starts a household with the user as its first member.
+func CreateHousehold(userID string, name string, displayName string) (*models.Household, error) {
+	if _, err := GetUserHousehold(userID); err == nil {
+		return nil, ErrAlreadyInHousehold
+	} else if !errors.Is(err, ErrNoHousehold) {
+		return nil, err
+	}
+
+	household := models.Household{Name: name, OwnerID: userID}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Retry the rare invite code collision rather than surfacing it
+		for attempt := 0; ; attempt++ {
+			code, err := newInviteCode()
+			if err != nil {
+				return err
+			}
+			var taken int64
+			if err := tx.Model(&models.Household{}).Unscoped().Where("invite_code = ?", code).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken == 0 {
+				household.InviteCode = code
+				break
+			}
+			if attempt == 5 {
+				return fmt.Errorf("could not generate a unique invite code")
+			}
+		}
+
+		if err := tx.Create(&household).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HouseholdMember{HouseholdID: household.ID, UserID: userID, DisplayName: displayName}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// JoinHousehold adds the user to the household with the given invite code.
+func JoinHousehold(userID string, inviteCode string, displayName string) (*models.Household, error) {
+	if _, err := GetUserHousehold(userID); err == nil {
+		return nil, ErrAlreadyInHousehold
+	} else if !errors.Is(err, ErrNoHousehold) {
+		return nil, err
+	}
+
+	var household models.Household
+	result := database.DB.Where("invite_code = ?", strings.ToUpper(strings.TrimSpace(inviteCode))).First(&household)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidInviteCode
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	member := models.HouseholdMember{HouseholdID: household.ID, UserID: userID, DisplayName: displayName}
+	if err := database.DB.Create(&member).Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// LeaveHousehold removes the user from their household. Expenses they shared stay in the
+// household's ledger. The household is closed when its last member leaves.
+func LeaveHousehold(userID string) (*models.Household, error) {
+	household, err := GetUserHousehold(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Hard delete so the user can join a household again under the unique user index
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.HouseholdMember{}).Error; err != nil {
+			return err
+		}
+
+		var remaining int64
+		if err := tx.Model(&models.HouseholdMember{}).Where("household_id = ?", household.ID).Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return tx.Delete(household).Error
+		}
+		if household.OwnerID == userID {
+			var next models.HouseholdMember
+			if err := tx.Where("household_id = ?", household.ID).Order("created_at asc").First(&next).Error; err != nil {
+				return err
+			}
+			return tx.Model(household).Update("owner_id", next.UserID).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// GetUserHousehold returns the household the user belongs to, or ErrNoHousehold.
+func GetUserHousehold(userID string) (*models.Household, error) {
+	var member models.HouseholdMember
+	result := database.DB.Where("user_id = ?", userID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNoHousehold
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var household models.Household
+	if err := database.DB.First(&household, member.HouseholdID).Error; err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+func GetHouseholdMembers(householdID uint) ([]models.HouseholdMember, error) {
+	var members []models.HouseholdMember
+	result := database.DB.Where("household_id = ?", householdID).Order("created_at asc").Find(&members)
+	return members, result.Error
+}
+
+// ShareExpenseWithHousehold moves one of the user's expenses into their household's ledger.
+func ShareExpenseWithHousehold(userID string, expenseID string) (*models.Household, error) {
+	household, err := GetUserHousehold(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setExpenseHousehold(userID, expenseID, &household.ID); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// UnshareExpense takes one of the user's expenses out of the household ledger, keeping it personal.
+func UnshareExpense(userID string, expenseID string) error {
+	return setExpenseHousehold(userID, expenseID, nil)
+}
+
+func setExpenseHousehold(userID string, expenseID string, householdID *uint) error {
+	expenseIDUint, err := strconv.ParseUint(expenseID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error converting expenseID to uint: %w", err)
+	}
+
+	result := database.DB.Model(&models.ExpensesLog{}).
+		Where("id = ? AND user_id = ?", expenseIDUint, userID).
+		Update("household_id", householdID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrExpenseNotFound
+	}
+	return nil
+}
+
+// GetExpensesByHouseholdAndRange returns every expense shared to a household within a report
+// range, from all of its members, newest first.
+func GetExpensesByHouseholdAndRange(householdID uint, rangeType string) ([]models.ExpensesLog, error) {
+	var expenses []models.ExpensesLog
+
+	startTime, err := rangeStartTime(rangeType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := database.DB.
+		Where("household_id = ? AND created_at >= ?", householdID, startTime).
+		Order("created_at desc").
+		Find(&expenses)
+
+	return expenses, result.Error
+}
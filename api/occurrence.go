@@ -0,0 +1,276 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecordReminderOccurrence records that the notification for a reminder's current due date was sent.
+func RecordReminderOccurrence(reminder models.RemindersLog, notifiedAt time.Time) error {
+	var occurrence models.ReminderOccurrence
+	result := database.DB.
+		Where(models.ReminderOccurrence{ReminderID: reminder.ID, DueDate: reminder.DueDate}).
+		Attrs(models.ReminderOccurrence{UserID: reminder.UserID, Status: models.ReminderPending}).
+		FirstOrCreate(&occurrence)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	result = database.DB.Model(&occurrence).Updates(map[string]interface{}{
+		"notified":    true,
+		"notified_at": notifiedAt,
+	})
+	return result.Error
+}
+
+// GetReminderOccurrences returns a reminder and its most recent occurrences, newest first,
+// scoped to the reminder's owner.
+func GetReminderOccurrences(userID string, reminderID string, limit int) (*models.RemindersLog, []models.ReminderOccurrence, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var occurrences []models.ReminderOccurrence
+	result := database.DB.
+		Where("reminder_id = ?", reminder.ID).
+		Order("due_date desc").
+		Limit(limit).
+		Find(&occurrences)
+	return reminder, occurrences, result.Error
+}
+
+// MarkReminderPaid records a payment against a reminder. The oldest unpaid occurrence that has
+// already been notified is marked paid; if there is none, the upcoming due date is paid ahead
+// and a recurring series moves on to its next due date. One-time reminders, and series with no
+// further occurrences, move to the paid status as a whole; recurring series stay active. If the reminder
+// has a category, the payment is also logged as an expense and linked to the occurrence.
+func MarkReminderPaid(userID string, reminderID string, paidAt time.Time) (*models.ReminderOccurrence, *models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !IsActiveReminderStatus(reminder.Status) {
+		return nil, nil, fmt.Errorf("%w: reminder %s is %s", ErrInvalidStatusTransition, reminderID, reminder.Status)
+	}
+
+	rule, recurring, err := utils.ReminderRule(reminder.Frequency, reminder.RRule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var occurrence models.ReminderOccurrence
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("reminder_id = ? AND status = ? AND notified = ?", reminder.ID, models.ReminderPending, true).
+			Order("due_date asc").
+			First(&occurrence)
+
+		paidAhead := errors.Is(result.Error, gorm.ErrRecordNotFound)
+		if result.Error != nil && !paidAhead {
+			return result.Error
+		}
+
+		if paidAhead {
+			occurrence = models.ReminderOccurrence{
+				ReminderID: reminder.ID,
+				UserID:     reminder.UserID,
+				DueDate:    reminder.DueDate,
+				Status:     models.ReminderPending,
+			}
+			if err := tx.Where(models.ReminderOccurrence{ReminderID: reminder.ID, DueDate: reminder.DueDate}).FirstOrCreate(&occurrence).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&occurrence).Updates(map[string]interface{}{"status": models.ReminderPaid, "paid_at": paidAt}).Error; err != nil {
+			return err
+		}
+		occurrence.Status = models.ReminderPaid
+		occurrence.PaidAt = &paidAt
+
+		if reminder.Category != "" {
+			if _, err := logOccurrenceExpense(tx, reminder, &occurrence, reminder.Category); err != nil {
+				return err
+			}
+		}
+
+		seriesEnded := !recurring || reminder.Notified
+		if recurring && paidAhead {
+			seriesStart := reminder.DueDate
+			if reminder.RecurrenceStart != nil {
+				seriesStart = *reminder.RecurrenceStart
+			}
+			nextDueDate, ok := rule.Next(seriesStart, reminder.DueDate)
+			if ok {
+				reminder.DueDate = nextDueDate
+				if err := tx.Model(reminder).Updates(map[string]interface{}{
+					"due_date":      nextDueDate,
+					"notified":      false,
+					"snoozed_until": nil,
+					"snooze_count":  0,
+				}).Error; err != nil {
+					return err
+				}
+			} else {
+				seriesEnded = true
+			}
+		}
+
+		if seriesEnded {
+			var unpaid int64
+			if err := tx.Model(&models.ReminderOccurrence{}).
+				Where("reminder_id = ? AND status = ?", reminder.ID, models.ReminderPending).
+				Count(&unpaid).Error; err != nil {
+				return err
+			}
+			if unpaid == 0 {
+				return transitionReminder(tx, reminder, models.ReminderPaid, paidAt, nil)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &occurrence, reminder, nil
+}
+
+// getOwnedOccurrence loads an occurrence and its reminder, scoped to the reminder's owner.
+func getOwnedOccurrence(tx *gorm.DB, userID string, occurrenceID string) (*models.ReminderOccurrence, *models.RemindersLog, error) {
+	occurrenceIDUint, err := strconv.ParseUint(occurrenceID, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error converting occurrenceID to uint: %w", err)
+	}
+
+	var occurrence models.ReminderOccurrence
+	if err := tx.Where("id = ? AND user_id = ?", occurrenceIDUint, userID).First(&occurrence).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var reminder models.RemindersLog
+	if err := tx.First(&reminder, occurrence.ReminderID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &occurrence, &reminder, nil
+}
+
+// logOccurrenceExpense creates the expense for a paid occurrence and links the two together.
+func logOccurrenceExpense(tx *gorm.DB, reminder *models.RemindersLog, occurrence *models.ReminderOccurrence, category string) (*models.ExpensesLog, error) {
+	expense := models.ExpensesLog{
+		Amount:               reminder.Amount,
+		Category:             category,
+		UserID:               reminder.UserID,
+		ReminderID:           &reminder.ID,
+		ReminderOccurrenceID: &occurrence.ID,
+	}
+	if err := tx.Create(&expense).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(occurrence).Update("expense_id", expense.ID).Error; err != nil {
+		return nil, err
+	}
+	occurrence.ExpenseID = &expense.ID
+	return &expense, nil
+}
+
+// LogReminderPaymentExpense logs a paid occurrence as an expense in the given category. The
+// category is remembered on the reminder so later payments are logged without asking again.
+func LogReminderPaymentExpense(userID string, occurrenceID string, category string) (*models.ExpensesLog, error) {
+	var expense *models.ExpensesLog
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		occurrence, reminder, err := getOwnedOccurrence(tx, userID, occurrenceID)
+		if err != nil {
+			return err
+		}
+		if occurrence.Status != models.ReminderPaid {
+			return fmt.Errorf("occurrence %s is not paid", occurrenceID)
+		}
+		if occurrence.ExpenseID != nil {
+			return fmt.Errorf("occurrence %s already has an expense", occurrenceID)
+		}
+
+		expense, err = logOccurrenceExpense(tx, reminder, occurrence, category)
+		if err != nil {
+			return err
+		}
+		return tx.Model(reminder).Update("category", category).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// UndoReminderPayment reverts a payment recorded with MarkReminderPaid and deletes the expense
+// logged for it, with its receipts. A payment made ahead of its due date puts the reminder back on that date.
+func UndoReminderPayment(userID string, occurrenceID string) (*models.RemindersLog, error) {
+	var reminder *models.RemindersLog
+	var receipts []models.ExpenseReceipt
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		occurrence, owned, err := getOwnedOccurrence(tx, userID, occurrenceID)
+		if err != nil {
+			return err
+		}
+		reminder = owned
+		if occurrence.Status != models.ReminderPaid {
+			return fmt.Errorf("occurrence %s is not paid", occurrenceID)
+		}
+
+		if occurrence.ExpenseID != nil {
+			if err := tx.Where("id = ? AND user_id = ?", *occurrence.ExpenseID, userID).Delete(&models.ExpensesLog{}).Error; err != nil {
+				return err
+			}
+			if receipts, err = deleteExpenseReceipts(tx, "expense_id = ?", *occurrence.ExpenseID); err != nil {
+				return err
+			}
+		}
+
+		// Proofs stay with the reminder but no longer point at a payment
+		if err := tx.Model(&models.PaymentProof{}).Where("occurrence_id = ?", occurrence.ID).Update("occurrence_id", nil).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if occurrence.Notified {
+			if err := tx.Model(occurrence).Updates(map[string]interface{}{"status": models.ReminderPending, "paid_at": nil, "expense_id": nil}).Error; err != nil {
+				return err
+			}
+		} else {
+			// Paid ahead: the occurrence was never notified, so drop it and restore the due date. It's
+			// removed for good so the due date can be recorded again under idx_reminder_occurrence.
+			if err := tx.Unscoped().Delete(occurrence).Error; err != nil {
+				return err
+			}
+			if reminder.DueDate.After(occurrence.DueDate) {
+				updates["due_date"] = occurrence.DueDate
+				updates["notified"] = false
+				reminder.DueDate = occurrence.DueDate
+				reminder.Notified = false
+			}
+		}
+
+		if reminder.Status == models.ReminderPaid {
+			return transitionReminder(tx, reminder, models.ReminderPending, time.Now(), updates)
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(reminder).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleteReceiptPhotos(userID, receipts)
+	return reminder, nil
+}
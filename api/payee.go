@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrPayeeNotFound = errors.New("payee not found")
+
+// activeReminderStatuses are the statuses of reminders still waiting to be paid.
+var activeReminderStatuses = []models.ReminderStatus{models.ReminderPending, models.ReminderOverdue}
+
+// SavePayee adds a payee to a user's address book.
+func SavePayee(userID string, name string, payment utils.PaymentDetails, defaultAmount *float64) (*models.Payee, error) {
+	existing, err := GetPayeeByName(userID, name)
+	if err != nil && !errors.Is(err, ErrPayeeNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("you already have a payee named %s", existing.Name)
+	}
+	if err := validatePayee(payment, defaultAmount); err != nil {
+		return nil, err
+	}
+
+	payee := models.Payee{
+		UserID:        userID,
+		Name:          name,
+		PaymentMethod: payment.Method,
+		AccountNumber: payment.AccountNumber,
+		BankName:      payment.BankName,
+		TransferRail:  payment.TransferRail,
+		DefaultAmount: defaultAmount,
+	}
+	result := database.DB.Create(&payee)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &payee, nil
+}
+
+// validatePayee checks a payee's payment details, using the default amount for transfer limits.
+func validatePayee(payment utils.PaymentDetails, defaultAmount *float64) error {
+	var amount float64
+	if defaultAmount != nil {
+		if *defaultAmount <= 0 {
+			return fmt.Errorf("%w: default amount must be greater than zero", ErrInvalidPaymentDetails)
+		}
+		amount = *defaultAmount
+	}
+	if err := utils.ValidatePaymentDetails(payment, amount); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPaymentDetails, err)
+	}
+	return nil
+}
+
+// GetPayeeByName looks up a user's payee by name, ignoring case.
+func GetPayeeByName(userID string, name string) (*models.Payee, error) {
+	var payee models.Payee
+	result := database.DB.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&payee)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrPayeeNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &payee, nil
+}
+
+func GetPayees(userID string) ([]models.Payee, error) {
+	var payees []models.Payee
+	result := database.DB.Where("user_id = ?", userID).Order("name asc").Find(&payees)
+	return payees, result.Error
+}
+
+// PayeeUpdate holds the payee fields to change; nil fields are left as they are.
+type PayeeUpdate struct {
+	Name               *string
+	Payment            *utils.PaymentDetails
+	DefaultAmount      *float64
+	ClearDefaultAmount bool
+}
+
+// UpdatePayee changes a user's payee. Pending and overdue reminders linked to the payee are
+// updated with the new name and payment details; paid reminders keep what they were paid with.
+func UpdatePayee(userID string, name string, update PayeeUpdate) (*models.Payee, error) {
+	payee, err := GetPayeeByName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil && !strings.EqualFold(*update.Name, payee.Name) {
+		if _, err := GetPayeeByName(userID, *update.Name); err == nil {
+			return nil, fmt.Errorf("you already have a payee named %s", *update.Name)
+		} else if !errors.Is(err, ErrPayeeNotFound) {
+			return nil, err
+		}
+	}
+
+	updates := map[string]interface{}{}
+	reminderUpdates := map[string]interface{}{}
+	if update.Name != nil {
+		updates["name"] = *update.Name
+		reminderUpdates["recipient"] = *update.Name
+		payee.Name = *update.Name
+	}
+	if update.DefaultAmount != nil {
+		updates["default_amount"] = *update.DefaultAmount
+		payee.DefaultAmount = update.DefaultAmount
+	} else if update.ClearDefaultAmount {
+		updates["default_amount"] = nil
+		payee.DefaultAmount = nil
+	}
+	if update.Payment != nil {
+		for column, value := range map[string]interface{}{
+			"payment_method": update.Payment.Method,
+			"account_number": update.Payment.AccountNumber,
+			"bank_name":      update.Payment.BankName,
+			"transfer_rail":  update.Payment.TransferRail,
+		} {
+			updates[column] = value
+			reminderUpdates[column] = value
+		}
+		payee.PaymentMethod = update.Payment.Method
+		payee.AccountNumber = update.Payment.AccountNumber
+		payee.BankName = update.Payment.BankName
+		payee.TransferRail = update.Payment.TransferRail
+	}
+	if err := validatePayee(payeePaymentDetails(payee), payee.DefaultAmount); err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return payee, nil
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(payee).Updates(updates).Error; err != nil {
+			return err
+		}
+		if len(reminderUpdates) == 0 {
+			return nil
+		}
+		return tx.Model(&models.RemindersLog{}).
+			Where("payee_id = ? AND status IN ?", payee.ID, activeReminderStatuses).
+			Updates(reminderUpdates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payee, nil
+}
+
+// DeletePayee removes a payee from a user's address book. Reminders for the payee are kept
+// with their own copy of the payment details and are unlinked. The payee is deleted for good
+// so the name is free to be saved again.
+func DeletePayee(userID string, name string) (*models.Payee, error) {
+	payee, err := GetPayeeByName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RemindersLog{}).Where("payee_id = ?", payee.ID).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(payee).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payee, nil
+}
+
+// payeePaymentDetails returns the payment details saved for a payee.
+func payeePaymentDetails(payee *models.Payee) utils.PaymentDetails {
+	return utils.PaymentDetails{
+		Method:        payee.PaymentMethod,
+		AccountNumber: payee.AccountNumber,
+		BankName:      payee.BankName,
+		TransferRail:  payee.TransferRail,
+	}
+}
+
+// findOrCreateReminderPayee returns the payee a new payment reminder belongs to, adding the
+// recipient to the address book when they aren't in it yet.
+func findOrCreateReminderPayee(tx *gorm.DB, userID string, recipient string, payment utils.PaymentDetails) (*models.Payee, error) {
+	var payee models.Payee
+	result := tx.
+		Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, recipient).
+		Attrs(models.Payee{
+			Name:          recipient,
+			PaymentMethod: payment.Method,
+			AccountNumber: payment.AccountNumber,
+			BankName:      payment.BankName,
+			TransferRail:  payment.TransferRail,
+		}).
+		FirstOrCreate(&payee, models.Payee{UserID: userID})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &payee, nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/storage"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrProofAlreadyAttached = errors.New("payment proof is already attached to a reminder")
+
+// imageExtensions maps the image types Messenger sends to file extensions for blob keys.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// SavePaymentProof stores an uploaded image in the blob store and records it as an unattached
+// payment proof for the user.
+func SavePaymentProof(userID string, image []byte, contentType string) (*models.PaymentProof, error) {
+	key := fmt.Sprintf("payment-proofs/%s/%d%s", userID, time.Now().UnixNano(), imageExtensions[contentType])
+	if err := storage.Blobs.Put(key, image); err != nil {
+		return nil, fmt.Errorf("failed to store payment proof: %w", err)
+	}
+
+	proof := models.PaymentProof{
+		UserID:      userID,
+		BlobKey:     key,
+		ContentType: contentType,
+		Size:        len(image),
+	}
+	if err := database.DB.Create(&proof).Error; err != nil {
+		deletePaymentProofImages(userID, []models.PaymentProof{proof})
+		return nil, err
+	}
+	return &proof, nil
+}
+
+func getOwnedPaymentProof(userID string, proofID string) (*models.PaymentProof, error) {
+	proofIDUint, err := strconv.ParseUint(proofID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error converting proofID to uint: %w", err)
+	}
+
+	var proof models.PaymentProof
+	if err := database.DB.Where("id = ? AND user_id = ?", proofIDUint, userID).First(&proof).Error; err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// AttachPaymentProof links an uploaded proof to one of the user's active reminders and marks
+// the reminder paid, the same as tapping Mark as Paid.
+func AttachPaymentProof(userID string, proofID string, reminderID string, paidAt time.Time) (*models.ReminderOccurrence, *models.RemindersLog, error) {
+	proof, err := getOwnedPaymentProof(userID, proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if proof.ReminderID != nil {
+		return nil, nil, ErrProofAlreadyAttached
+	}
+
+	occurrence, reminder, err := MarkReminderPaid(userID, reminderID, paidAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = database.DB.Model(proof).Updates(map[string]interface{}{
+		"reminder_id":   reminder.ID,
+		"occurrence_id": occurrence.ID,
+	}).Error
+	if err != nil {
+		// Without the proof linked the payment would be recorded with nothing to show for it
+		if _, undoErr := UndoReminderPayment(userID, strconv.FormatUint(uint64(occurrence.ID), 10)); undoErr != nil {
+			fmt.Printf("Error undoing payment %d after failing to attach proof %s for user %s: %v\n", occurrence.ID, proofID, userID, undoErr)
+		}
+		return nil, nil, err
+	}
+	return occurrence, reminder, nil
+}
+
+// DiscardPaymentProof deletes an unattached proof and its image.
+func DiscardPaymentProof(userID string, proofID string) error {
+	proof, err := getOwnedPaymentProof(userID, proofID)
+	if err != nil {
+		return err
+	}
+	if proof.ReminderID != nil {
+		return ErrProofAlreadyAttached
+	}
+
+	if err := database.DB.Delete(proof).Error; err != nil {
+		return err
+	}
+	return storage.Blobs.Delete(proof.BlobKey)
+}
+
+// GetReminderPaymentProofs returns the proofs attached to a user's reminder, oldest first.
+func GetReminderPaymentProofs(userID string, reminderID string) ([]models.PaymentProof, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var proofs []models.PaymentProof
+	result := database.DB.Where("reminder_id = ?", reminder.ID).Order("created_at asc").Find(&proofs)
+	return proofs, result.Error
+}
+
+// GetPaymentProofImage reads a proof's image from the blob store.
+func GetPaymentProofImage(proof models.PaymentProof) ([]byte, error) {
+	return storage.Blobs.Get(proof.BlobKey)
+}
+
+// deletePaymentProofs deletes the proofs matching the conditions inside tx and returns them, so
+// their images can be removed with deletePaymentProofImages once tx commits.
+func deletePaymentProofs(tx *gorm.DB, query string, args ...interface{}) ([]models.PaymentProof, error) {
+	var proofs []models.PaymentProof
+	if err := tx.Where(query, args...).Find(&proofs).Error; err != nil {
+		return nil, err
+	}
+	if len(proofs) == 0 {
+		return nil, nil
+	}
+	return proofs, tx.Delete(&proofs).Error
+}
+
+// deletePaymentProofImages removes the images of deleted proofs from the blob store. A leftover
+// file is only logged, since the proofs themselves are already gone.
+func deletePaymentProofImages(userID string, proofs []models.PaymentProof) {
+	for _, proof := range proofs {
+		if err := storage.Blobs.Delete(proof.BlobKey); err != nil {
+			fmt.Printf("Error deleting payment proof image %s for user %s: %v\n", proof.BlobKey, userID, err)
+		}
+	}
+}
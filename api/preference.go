@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"time"
+)
+
+// GetUserPreference returns the preferences for a user, creating a row with the defaults if none exists yet.
+func GetUserPreference(userID string) (*models.UserPreference, error) {
+	var preference models.UserPreference
+	result := database.DB.
+		Where(models.UserPreference{UserID: userID}).
+		Attrs(models.UserPreference{ReportFrequency: "none", AnomalySensitivity: "medium", ReminderLeadDays: "3,1", ReminderTime: "09:00", Timezone: "Asia/Manila"}).
+		FirstOrCreate(&preference)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &preference, nil
+}
+
+// UpdateAnomalySensitivity sets how aggressively unusual expenses are flagged for a user.
+func UpdateAnomalySensitivity(userID string, level string) error {
+	if !utils.IsValidSensitivity(level) {
+		return fmt.Errorf("invalid sensitivity level: %s", level)
+	}
+
+	preference, err := GetUserPreference(userID)
+	if err != nil {
+		return err
+	}
+
+	result := database.DB.Model(preference).Update("anomaly_sensitivity", level)
+	return result.Error
+}
+
+// UpdateReminderLeadDays sets the default advance-notice days used by reminders that don't set their own.
+func UpdateReminderLeadDays(userID string, leadDays string) error {
+	if _, err := utils.ParseLeadDays(leadDays); err != nil {
+		return err
+	}
+
+	preference, err := GetUserPreference(userID)
+	if err != nil {
+		return err
+	}
+
+	result := database.DB.Model(preference).Update("reminder_lead_days", leadDays)
+	return result.Error
+}
+
+// UpdateReminderTime sets the default time of day ("15:04") for reminders without their own due time.
+func UpdateReminderTime(userID string, reminderTime string) error {
+	if _, _, err := utils.ParseTimeOfDay(reminderTime); err != nil {
+		return err
+	}
+
+	preference, err := GetUserPreference(userID)
+	if err != nil {
+		return err
+	}
+
+	result := database.DB.Model(preference).Update("reminder_time", reminderTime)
+	return result.Error
+}
+
+// UpdateTimezone sets the IANA timezone (e.g. "Asia/Manila") reminders are evaluated in.
+func UpdateTimezone(userID string, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("unknown timezone: %s", timezone)
+	}
+
+	preference, err := GetUserPreference(userID)
+	if err != nil {
+		return err
+	}
+
+	result := database.DB.Model(preference).Update("timezone", timezone)
+	return result.Error
+}
+
+// UserLocation returns the time.Location for a user's timezone, falling back to Asia/Manila.
+func UserLocation(preference *models.UserPreference) *time.Location {
+	loc, err := time.LoadLocation(preference.Timezone)
+	if err != nil {
+		fmt.Printf("Unknown timezone %s for user %s, using Asia/Manila: %v\n", preference.Timezone, preference.UserID, err)
+		loc, _ = time.LoadLocation("Asia/Manila")
+	}
+	return loc
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/storage"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func getOwnedExpense(userID string, expenseID string) (*models.ExpensesLog, error) {
+	expenseIDUint, err := strconv.ParseUint(expenseID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error converting expenseID to uint: %w", err)
+	}
+
+	var expense models.ExpensesLog
+	result := database.DB.Where("id = ? AND user_id = ?", expenseIDUint, userID).First(&expense)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrExpenseNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &expense, nil
+}
+
+// GetLatestExpense returns the expense the user logged most recently.
+func GetLatestExpense(userID string) (*models.ExpensesLog, error) {
+	expenses, _, err := GetRecentExpenses(userID, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(expenses) == 0 {
+		return nil, ErrExpenseNotFound
+	}
+	return &expenses[0], nil
+}
+
+// SaveExpenseReceipt stores a receipt photo in the blob store and links it to one of the
+// user's expenses.
+func SaveExpenseReceipt(userID string, expenseID string, image []byte, contentType string) (*models.ExpenseReceipt, *models.ExpensesLog, error) {
+	expense, err := getOwnedExpense(userID, expenseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := fmt.Sprintf("receipts/%s/%d%s", userID, time.Now().UnixNano(), imageExtensions[contentType])
+	if err := storage.Blobs.Put(key, image); err != nil {
+		return nil, nil, fmt.Errorf("failed to store receipt: %w", err)
+	}
+
+	receipt := models.ExpenseReceipt{
+		UserID:      userID,
+		ExpenseID:   expense.ID,
+		BlobKey:     key,
+		ContentType: contentType,
+		Size:        len(image),
+	}
+	if err := database.DB.Create(&receipt).Error; err != nil {
+		storage.Blobs.Delete(key)
+		return nil, nil, err
+	}
+	return &receipt, expense, nil
+}
+
+// GetExpenseReceipts returns a user's expense with the receipts attached to it, oldest first.
+func GetExpenseReceipts(userID string, expenseID string) (*models.ExpensesLog, []models.ExpenseReceipt, error) {
+	expense, err := getOwnedExpense(userID, expenseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var receipts []models.ExpenseReceipt
+	result := database.DB.Where("expense_id = ?", expense.ID).Order("created_at asc").Find(&receipts)
+	return expense, receipts, result.Error
+}
+
+// GetExpensesWithReceipts returns the user's most recent expenses that have receipts, newest
+// first, with the number of receipts on each.
+func GetExpensesWithReceipts(userID string, limit int) ([]models.ExpensesLog, map[uint]int, error) {
+	var counts []struct {
+		ExpenseID uint
+		Count     int
+	}
+	result := database.DB.Model(&models.ExpenseReceipt{}).
+		Select("expense_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("expense_id").
+		Scan(&counts)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if len(counts) == 0 {
+		return nil, nil, nil
+	}
+
+	receiptCounts := make(map[uint]int)
+	expenseIDs := make([]uint, 0, len(counts))
+	for _, count := range counts {
+		receiptCounts[count.ExpenseID] = count.Count
+		expenseIDs = append(expenseIDs, count.ExpenseID)
+	}
+
+	var expenses []models.ExpensesLog
+	result = database.DB.
+		Where("id IN ? AND user_id = ?", expenseIDs, userID).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&expenses)
+	return expenses, receiptCounts, result.Error
+}
+
+// GetReceiptImage reads a receipt's image from the blob store.
+func GetReceiptImage(receipt models.ExpenseReceipt) ([]byte, error) {
+	return storage.Blobs.Get(receipt.BlobKey)
+}
+
+// deleteExpenseReceipts deletes the receipts matching the conditions inside tx, for expenses
+// that are being deleted, and returns them so their photos can be removed with
+// deleteReceiptPhotos once tx commits.
+func deleteExpenseReceipts(tx *gorm.DB, query string, args ...interface{}) ([]models.ExpenseReceipt, error) {
+	var receipts []models.ExpenseReceipt
+	if err := tx.Where(query, args...).Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	return receipts, tx.Delete(&receipts).Error
+}
+
+// deleteReceiptPhotos removes the photos of deleted receipts from the blob store. A leftover
+// file is only logged, since the receipts themselves are already gone.
+func deleteReceiptPhotos(userID string, receipts []models.ExpenseReceipt) {
+	for _, receipt := range receipts {
+		if err := storage.Blobs.Delete(receipt.BlobKey); err != nil {
+			fmt.Printf("Error deleting receipt photo %s for user %s: %v\n", receipt.BlobKey, userID, err)
+		}
+	}
+}
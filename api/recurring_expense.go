@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrRecurringExpenseNotDue = errors.New("recurring expense already logged")
+
+// recurringLookahead covers the spread of user timezones, since due dates are compared against
+// each user's own reminder time once loaded.
+const recurringLookahead = 24 * time.Hour
+
+// SaveRecurringExpense schedules a fixed cost to be logged on every date of its rule. Dates
+// before today are not logged retroactively; the first run is the next date from today.
+func SaveRecurringExpense(userID string, amount float64, category string, start time.Time, rule utils.RecurrenceRule) (*models.RecurringExpense, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	recurring := models.RecurringExpense{
+		UserID:    userID,
+		Amount:    amount,
+		Category:  category,
+		RRule:     rule.String(),
+		StartDate: start,
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, start.Location())
+	nextRun := start
+	if start.Before(today) {
+		next, ok := rule.Next(start, today.Add(-time.Nanosecond))
+		if !ok {
+			return nil, fmt.Errorf("the schedule has no dates left")
+		}
+		nextRun = next
+	}
+	recurring.NextRunAt = &nextRun
+
+	if err := database.DB.Create(&recurring).Error; err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+func GetRecurringExpenses(userID string) ([]models.RecurringExpense, error) {
+	var recurring []models.RecurringExpense
+	result := database.DB.Where("user_id = ?", userID).Order("next_run_at asc").Find(&recurring)
+	return recurring, result.Error
+}
+
+// StopRecurringExpense stops a user's recurring expense. Expenses already logged are kept.
+func StopRecurringExpense(userID string, recurringID string) (*models.RecurringExpense, error) {
+	recurringIDUint, err := strconv.ParseUint(recurringID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error converting recurringID to uint: %w", err)
+	}
+
+	var recurring models.RecurringExpense
+	if err := database.DB.Where("id = ? AND user_id = ?", recurringIDUint, userID).First(&recurring).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Delete(&recurring).Error; err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+// GetDueRecurringExpenses returns recurring expenses whose next date is at or before now,
+// give or take a day for timezones.
+func GetDueRecurringExpenses(now time.Time) ([]models.RecurringExpense, error) {
+	var recurring []models.RecurringExpense
+	result := database.DB.
+		Where("next_run_at IS NOT NULL AND next_run_at <= ?", now.Add(recurringLookahead)).
+		Find(&recurring)
+	return recurring, result.Error
+}
+
+// LogRecurringExpense records the expense for a recurring expense's current date, dated loggedAt,
+// and moves it on to its next date. The update is conditional on the date being logged, so a
+// date is never logged twice.
+func LogRecurringExpense(recurring *models.RecurringExpense, loggedAt time.Time) (*models.ExpensesLog, error) {
+	if recurring.NextRunAt == nil {
+		return nil, ErrRecurringExpenseNotDue
+	}
+	rule, err := utils.ParseRRule(recurring.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	runDate := *recurring.NextRunAt
+	var nextRun *time.Time
+	if next, ok := rule.Next(recurring.StartDate, runDate); ok {
+		nextRun = &next
+	}
+
+	expense := models.ExpensesLog{
+		Amount:             recurring.Amount,
+		Category:           recurring.Category,
+		UserID:             recurring.UserID,
+		RecurringExpenseID: &recurring.ID,
+	}
+	expense.CreatedAt = loggedAt
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RecurringExpense{}).
+			Where("id = ? AND next_run_at = ?", recurring.ID, runDate).
+			Updates(map[string]interface{}{"next_run_at": nextRun, "last_logged_at": loggedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecurringExpenseNotDue
+		}
+		return tx.Create(&expense).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recurring.NextRunAt = nextRun
+	recurring.LastLoggedAt = &loggedAt
+	return &expense, nil
+}
@@ -1,30 +1,62 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"quickyexpensetracker/database"
 	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
 	"strconv"
 	"time"
+
+	"gorm.io/gorm"
 )
 
-func SaveReminder(userID string, amount float64, accountName string, gcashNumber string, dueDate time.Time, paymentMethod string, status string, reminderType string, frequency string) error {
-	reminder := models.RemindersLog{
-		Amount:        amount,
-		GcashNumber:   gcashNumber,
-		Recipient:     accountName,
-		DueDate:       dueDate,
-		PaymentMethod: paymentMethod,
-		Status:        status,
-		UserID:        userID,
-		Notified:      false, // Explicitly set Notified to false
-		ReminderType:  reminderType,
-		Frequency:     frequency,
+var ErrInvalidPaymentDetails = errors.New("invalid payment details")
+
+// SaveReminder creates a reminder; every reminder starts out pending. Payment reminders must
+// carry the recipient details their payment method needs, and are linked to the payee of that
+// name, who is added to the address book if new.
+func SaveReminder(userID string, amount float64, recipient string, payment utils.PaymentDetails, dueDate time.Time, reminderType string, frequency string, leadDays string, dueTimeSet bool, rrule string) error {
+	if reminderType == "payment" {
+		if err := utils.ValidatePaymentDetails(payment, amount); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPaymentDetails, err)
+		}
 	}
 
-	result := database.DB.Create(&reminder)
+	now := time.Now()
+	reminder := models.RemindersLog{
+		Amount:          amount,
+		AccountNumber:   payment.AccountNumber,
+		BankName:        payment.BankName,
+		TransferRail:    payment.TransferRail,
+		Recipient:       recipient,
+		DueDate:         dueDate,
+		PaymentMethod:   payment.Method,
+		Status:          models.ReminderPending,
+		StatusChangedAt: &now,
+		UserID:          userID,
+		Notified:        false, // Explicitly set Notified to false
+		ReminderType:    reminderType,
+		Frequency:       frequency,
+		LeadDays:        leadDays,
+		DueTimeSet:      dueTimeSet,
+		RRule:           rrule,
+	}
+	if rrule != "" {
+		reminder.RecurrenceStart = &dueDate
+	}
 
-	return result.Error
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if reminderType == "payment" {
+			payee, err := findOrCreateReminderPayee(tx, userID, recipient, payment)
+			if err != nil {
+				return err
+			}
+			reminder.PayeeID = &payee.ID
+		}
+		return tx.Create(&reminder).Error
+	})
 }
 
 func GetReminderByID(reminderID string) (*models.RemindersLog, error) {
@@ -40,7 +72,22 @@ func GetReminderByID(reminderID string) (*models.RemindersLog, error) {
 	return &reminder, nil
 }
 
-func GetReminders(userID string, status string) ([]models.RemindersLog, error) {
+// qrCodeSize is the width and height in pixels of payment QR images.
+const qrCodeSize = 512
+
+// getOwnedReminder loads a reminder, scoped to its owner.
+func getOwnedReminder(userID string, reminderID string) (*models.RemindersLog, error) {
+	reminder, err := GetReminderByID(reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder.UserID != userID {
+		return nil, fmt.Errorf("reminder %s does not belong to user", reminderID)
+	}
+	return reminder, nil
+}
+
+func GetReminders(userID string, status models.ReminderStatus) ([]models.RemindersLog, error) {
 	var reminders []models.RemindersLog
 
 	query := database.DB.Where("user_id = ?", userID)
@@ -53,31 +100,228 @@ func GetReminders(userID string, status string) ([]models.RemindersLog, error) {
 	return reminders, result.Error
 }
 
+// GetActiveReminders returns a user's pending and overdue reminders, soonest due first.
+func GetActiveReminders(userID string, limit int) ([]models.RemindersLog, error) {
+	var reminders []models.RemindersLog
+	result := database.DB.
+		Where("user_id = ? AND status IN ?", userID, activeReminderStatuses).
+		Order("due_date asc").
+		Limit(limit).
+		Find(&reminders)
+	return reminders, result.Error
+}
+
+// DeleteRemindersByUser removes all of a user's reminders along with their payment proofs.
 func DeleteRemindersByUser(userID string) error {
-	result := database.DB.Where("user_id = ?", userID).Delete(&models.RemindersLog{})
-	return result.Error
+	var proofs []models.PaymentProof
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RemindersLog{}).Error; err != nil {
+			return err
+		}
+		var err error
+		proofs, err = deletePaymentProofs(tx, "user_id = ?", userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	deletePaymentProofImages(userID, proofs)
+	return nil
+}
+
+// ReminderUpdate holds the reminder fields to change; nil fields are left as they are.
+type ReminderUpdate struct {
+	Amount        *float64
+	Recipient     *string
+	AccountNumber *string
+	DueDate       *time.Time
+	DueTimeSet    bool // Whether DueDate carries a time of day
+}
+
+// UpdateReminder changes the details of a user's reminder. A new due date also becomes the start
+// of a recurring series; use RescheduleReminder to move only the upcoming due date. A payment
+// reminder given a new recipient is relinked to that payee, who is added if they're new.
+func UpdateReminder(userID string, reminderID string, update ReminderUpdate) (*models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if update.Amount != nil {
+		if *update.Amount <= 0 {
+			return nil, fmt.Errorf("amount must be greater than zero")
+		}
+		updates["amount"] = *update.Amount
+		reminder.Amount = *update.Amount
+	}
+	if update.Recipient != nil {
+		updates["recipient"] = *update.Recipient
+		reminder.Recipient = *update.Recipient
+	}
+	if update.AccountNumber != nil {
+		updates["account_number"] = *update.AccountNumber
+		reminder.AccountNumber = *update.AccountNumber
+	}
+	if (update.Amount != nil || update.AccountNumber != nil) && reminder.ReminderType == "payment" {
+		if err := utils.ValidatePaymentDetails(reminderPaymentDetails(reminder), reminder.Amount); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentDetails, err)
+		}
+	}
+	if update.DueDate != nil {
+		for column, value := range dueDateUpdates(reminder, *update.DueDate, update.DueTimeSet) {
+			updates[column] = value
+		}
+		if reminder.RRule != "" {
+			updates["recurrence_start"] = *update.DueDate
+			reminder.RecurrenceStart = update.DueDate
+		}
+	}
+	if len(updates) == 0 {
+		return reminder, nil
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if update.Recipient != nil && reminder.ReminderType == "payment" {
+			// A new recipient is a different payee, so relink the reminder as when it was created
+			payee, err := findOrCreateReminderPayee(tx, userID, reminder.Recipient, reminderPaymentDetails(reminder))
+			if err != nil {
+				return err
+			}
+			updates["payee_id"] = payee.ID
+			reminder.PayeeID = &payee.ID
+		}
+		if update.DueDate != nil && reminder.Status == models.ReminderOverdue {
+			return transitionReminder(tx, reminder, models.ReminderPending, time.Now(), updates)
+		}
+		return tx.Model(reminder).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
 }
 
-// GetGcashDeepLink returns a hardcoded GCash deep link.
-// reminderID is not used currently but is kept for future enhancements.
-func GetGcashDeepLink(reminderID string) (string, error) {
-	return "gcash://", nil
+// RescheduleReminder moves the upcoming due date of a user's pending or overdue reminder. The
+// notification, snooze and overdue state for the old date is cleared, and an overdue reminder
+// goes back to pending.
+func RescheduleReminder(userID string, reminderID string, dueDate time.Time, dueTimeSet bool) (*models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsActiveReminderStatus(reminder.Status) {
+		return nil, fmt.Errorf("%w: reminder %s is %s", ErrInvalidStatusTransition, reminderID, reminder.Status)
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := dueDateUpdates(reminder, dueDate, dueTimeSet)
+		if reminder.Status == models.ReminderOverdue {
+			return transitionReminder(tx, reminder, models.ReminderPending, time.Now(), updates)
+		}
+		return tx.Model(reminder).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
 }
 
-func UpdateReminderStatus(reminderID string, newStatus string) error {
-	reminderIDUint, err := strconv.ParseUint(reminderID, 10, 64)
+// dueDateUpdates returns the columns to write when a reminder moves to a new due date, and
+// applies them to reminder. The status is left to the caller.
+func dueDateUpdates(reminder *models.RemindersLog, dueDate time.Time, dueTimeSet bool) map[string]interface{} {
+	updates := map[string]interface{}{
+		"due_date":          dueDate,
+		"due_time_set":      dueTimeSet,
+		"notified":          false,
+		"snoozed_until":     nil,
+		"snooze_count":      0,
+		"escalation_count":  0,
+		"last_escalated_at": nil,
+	}
+	reminder.DueDate = dueDate
+	reminder.DueTimeSet = dueTimeSet
+	reminder.Notified = false
+	reminder.SnoozedUntil = nil
+	reminder.SnoozeCount = 0
+	reminder.EscalationCount = 0
+	reminder.LastEscalatedAt = nil
+	return updates
+}
+
+// DeleteReminder removes one of a user's reminders along with its notification, snooze and
+// occurrence history and its payment proofs. Expenses logged from its payments are kept.
+func DeleteReminder(userID string, reminderID string) error {
+	reminder, err := getOwnedReminder(userID, reminderID)
 	if err != nil {
 		return err
 	}
 
-	result := database.DB.Model(&models.RemindersLog{}).Where("id = ?", reminderIDUint).Update("status", newStatus)
-	return result.Error
+	var proofs []models.PaymentProof
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reminder_id = ?", reminder.ID).Delete(&models.ReminderOccurrence{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reminder_id = ?", reminder.ID).Delete(&models.ReminderSnooze{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reminder_id = ?", reminder.ID).Delete(&models.ReminderNotification{}).Error; err != nil {
+			return err
+		}
+		var err error
+		if proofs, err = deletePaymentProofs(tx, "reminder_id = ?", reminder.ID); err != nil {
+			return err
+		}
+		return tx.Delete(reminder).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	deletePaymentProofImages(userID, proofs)
+	return nil
+}
+
+// reminderPaymentDetails returns the recipient details stored on a reminder.
+func reminderPaymentDetails(reminder *models.RemindersLog) utils.PaymentDetails {
+	return utils.PaymentDetails{
+		Method:        utils.GetPaymentMethod(reminder.PaymentMethod).Key,
+		AccountNumber: reminder.AccountNumber,
+		BankName:      reminder.BankName,
+		TransferRail:  reminder.TransferRail,
+	}
+}
+
+// GetReminderPaymentQR builds the QR Ph payload for paying a user's reminder, pre-filled with
+// the recipient's account and the amount due, and renders it as a PNG image. The image is nil
+// when the reminder's payment method can't be paid by QR code.
+func GetReminderPaymentQR(userID string, reminderID string) (*models.RemindersLog, []byte, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payment, ok := utils.PaymentQR(*reminder)
+	if !ok {
+		return reminder, nil, nil
+	}
+	payload, err := utils.BuildQRPhPayload(payment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	png, err := utils.RenderQRCodePNG(payload, qrCodeSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reminder, png, nil
 }
 
 // GetPendingUnnotifiedReminders retrieves all reminders that are pending and for which notifications have not yet been sent.
 func GetPendingUnnotifiedReminders() ([]models.RemindersLog, error) {
 	var reminders []models.RemindersLog
-	result := database.DB.Where("status = ? AND notified = ?", "pending", false).Find(&reminders)
+	result := database.DB.Where("status = ? AND notified = ?", models.ReminderPending, false).Find(&reminders)
 	return reminders, result.Error
 }
 
@@ -99,6 +343,120 @@ func UpdateReminderDueDateAndNotifiedStatus(reminderID string, newDueDate time.T
 		return fmt.Errorf("error converting reminderID to uint: %w", err)
 	}
 
-	result := database.DB.Model(&models.RemindersLog{}).Where("id = ?", reminderIDUint).Updates(models.RemindersLog{DueDate: newDueDate, Notified: notified})
+	// A map is used so that notified=false is written; snoozes apply to a single due date.
+	result := database.DB.Model(&models.RemindersLog{}).Where("id = ?", reminderIDUint).Updates(map[string]interface{}{
+		"due_date":      newDueDate,
+		"notified":      notified,
+		"snoozed_until": nil,
+		"snooze_count":  0,
+	})
+	return result.Error
+}
+
+// HasSentReminderStage reports whether the advance notice for a reminder's due date and lead time was already sent.
+func HasSentReminderStage(reminderID uint, dueDate time.Time, leadDays int) (bool, error) {
+	var count int64
+	result := database.DB.Model(&models.ReminderNotification{}).
+		Where("reminder_id = ? AND due_date = ? AND lead_days = ?", reminderID, dueDate, leadDays).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// RecordReminderStage marks the advance notice for a reminder's due date and lead time as sent.
+func RecordReminderStage(reminderID uint, dueDate time.Time, leadDays int) error {
+	notification := models.ReminderNotification{
+		ReminderID: reminderID,
+		DueDate:    dueDate,
+		LeadDays:   leadDays,
+	}
+	result := database.DB.Create(&notification)
+	return result.Error
+}
+
+// MaxReminderSnoozes is how many times a reminder can be snoozed for the same due date.
+const MaxReminderSnoozes = 3
+
+var ErrSnoozeLimitReached = errors.New("snooze limit reached")
+
+// SnoozeReminder holds back a reminder's notifications until the given time and records the
+// snooze in its history. The reminder's due date is left unchanged.
+func SnoozeReminder(userID string, reminderID string, option string, until time.Time) (*models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsActiveReminderStatus(reminder.Status) {
+		return nil, fmt.Errorf("%w: reminder %s is %s", ErrInvalidStatusTransition, reminderID, reminder.Status)
+	}
+	if reminder.SnoozeCount >= MaxReminderSnoozes {
+		return nil, ErrSnoozeLimitReached
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		snooze := models.ReminderSnooze{
+			ReminderID:   reminder.ID,
+			DueDate:      reminder.DueDate,
+			Option:       option,
+			SnoozedUntil: until,
+		}
+		if err := tx.Create(&snooze).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(reminder).Updates(map[string]interface{}{
+			"snoozed_until": until,
+			"snooze_count":  gorm.Expr("snooze_count + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reminder.SnoozedUntil = &until
+	reminder.SnoozeCount++
+	return reminder, nil
+}
+
+// GetElapsedSnoozedReminders retrieves pending or overdue reminders whose snooze has run out.
+func GetElapsedSnoozedReminders(now time.Time) ([]models.RemindersLog, error) {
+	var reminders []models.RemindersLog
+	result := database.DB.Where("status IN ? AND snoozed_until IS NOT NULL AND snoozed_until <= ?", []models.ReminderStatus{models.ReminderPending, models.ReminderOverdue}, now).Find(&reminders)
+	return reminders, result.Error
+}
+
+// ClearReminderSnooze removes the snooze from a reminder, keeping its snooze count.
+func ClearReminderSnooze(reminderID uint) error {
+	result := database.DB.Model(&models.RemindersLog{}).Where("id = ?", reminderID).Update("snoozed_until", nil)
 	return result.Error
 }
+
+// GetEscalatableReminders retrieves one-time payment reminders that were already notified but
+// are still unpaid, so overdue nudges can be sent for them.
+func GetEscalatableReminders() ([]models.RemindersLog, error) {
+	var reminders []models.RemindersLog
+	result := database.DB.
+		Where("reminder_type = ? AND notified = ? AND status IN ?", "payment", true, []models.ReminderStatus{models.ReminderPending, models.ReminderOverdue}).
+		Where("frequency IN ?", []string{"once", ""}).
+		Find(&reminders)
+	return reminders, result.Error
+}
+
+// RecordReminderEscalation counts the overdue nudge just sent for a reminder, moving it from
+// pending to overdue on the first one.
+func RecordReminderEscalation(reminderID uint, escalatedAt time.Time) error {
+	var reminder models.RemindersLog
+	if err := database.DB.First(&reminder, reminderID).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"escalation_count":  gorm.Expr("escalation_count + 1"),
+		"last_escalated_at": escalatedAt,
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if reminder.Status == models.ReminderOverdue {
+			return tx.Model(&reminder).Updates(updates).Error
+		}
+		return transitionReminder(tx, &reminder, models.ReminderOverdue, escalatedAt, updates)
+	})
+}
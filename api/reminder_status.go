@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid reminder status transition")
+
+// reminderTransitions lists the statuses each reminder status can move to. Cancelled and
+// skipped reminders are final; a paid reminder only goes back to pending when its payment is undone.
+var reminderTransitions = map[models.ReminderStatus][]models.ReminderStatus{
+	models.ReminderPending:   {models.ReminderOverdue, models.ReminderPaid, models.ReminderCancelled, models.ReminderSkipped},
+	models.ReminderOverdue:   {models.ReminderPending, models.ReminderPaid, models.ReminderCancelled, models.ReminderSkipped},
+	models.ReminderPaid:      {models.ReminderPending},
+	models.ReminderCancelled: {},
+	models.ReminderSkipped:   {},
+}
+
+// CanTransitionReminder reports whether a reminder may move from one status to another.
+func CanTransitionReminder(from models.ReminderStatus, to models.ReminderStatus) bool {
+	for _, allowed := range reminderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActiveReminderStatus reports whether a reminder with this status still expects a payment.
+func IsActiveReminderStatus(status models.ReminderStatus) bool {
+	return status == models.ReminderPending || status == models.ReminderOverdue
+}
+
+// transitionReminder moves a reminder to a new status along with any other column updates,
+// stamps the change and records it in the reminder's status history. The write only succeeds
+// if the reminder still has the status it was loaded with.
+func transitionReminder(tx *gorm.DB, reminder *models.RemindersLog, to models.ReminderStatus, at time.Time, updates map[string]interface{}) error {
+	from := reminder.Status
+	if !CanTransitionReminder(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+	}
+
+	columns := map[string]interface{}{}
+	for column, value := range updates {
+		columns[column] = value
+	}
+	columns["status"] = to
+	columns["status_changed_at"] = at
+
+	result := tx.Model(&models.RemindersLog{}).Where("id = ? AND status = ?", reminder.ID, from).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: reminder %d is no longer %s", ErrInvalidStatusTransition, reminder.ID, from)
+	}
+
+	change := models.ReminderStatusChange{
+		ReminderID: reminder.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedAt:  at,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return err
+	}
+
+	reminder.Status = to
+	reminder.StatusChangedAt = &at
+	return nil
+}
+
+// CancelReminder stops a user's pending or overdue reminder. It keeps its history but no
+// further notifications are sent.
+func CancelReminder(userID string, reminderID string) (*models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return transitionReminder(tx, reminder, models.ReminderCancelled, time.Now(), map[string]interface{}{"snoozed_until": nil})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// SkipReminder skips the upcoming payment of a user's reminder. A recurring series moves on to
+// its next due date and stays active; a one-time reminder, or the last payment of a series, is
+// marked skipped.
+func SkipReminder(userID string, reminderID string) (*models.RemindersLog, error) {
+	reminder, err := getOwnedReminder(userID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if !IsActiveReminderStatus(reminder.Status) {
+		return nil, fmt.Errorf("%w: reminder %s is %s", ErrInvalidStatusTransition, reminderID, reminder.Status)
+	}
+
+	rule, recurring, err := utils.ReminderRule(reminder.Frequency, reminder.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		occurrence := models.ReminderOccurrence{
+			ReminderID: reminder.ID,
+			UserID:     reminder.UserID,
+			DueDate:    reminder.DueDate,
+		}
+		if err := tx.Where(models.ReminderOccurrence{ReminderID: reminder.ID, DueDate: reminder.DueDate}).FirstOrCreate(&occurrence).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&occurrence).Update("status", models.ReminderSkipped).Error; err != nil {
+			return err
+		}
+
+		if recurring && !reminder.Notified {
+			seriesStart := reminder.DueDate
+			if reminder.RecurrenceStart != nil {
+				seriesStart = *reminder.RecurrenceStart
+			}
+			if nextDueDate, ok := rule.Next(seriesStart, reminder.DueDate); ok {
+				updates := dueDateUpdates(reminder, nextDueDate, reminder.DueTimeSet)
+				if reminder.Status == models.ReminderOverdue {
+					return transitionReminder(tx, reminder, models.ReminderPending, now, updates)
+				}
+				return tx.Model(reminder).Updates(updates).Error
+			}
+		}
+
+		return transitionReminder(tx, reminder, models.ReminderSkipped, now, map[string]interface{}{"snoozed_until": nil})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SaveSplitExpense records an expense the user paid for and shared. The payer's share is logged
+// as a regular expense and each other person's share is kept as an amount they owe.
+func SaveSplitExpense(userID string, category string, total float64, payerShare float64, shares []utils.SplitShare) (*models.SplitExpense, *models.ExpensesLog, error) {
+	split := models.SplitExpense{
+		UserID:      userID,
+		Category:    category,
+		TotalAmount: total,
+	}
+	var expense *models.ExpensesLog
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if payerShare > 0 {
+			expense = &models.ExpensesLog{Amount: payerShare, Category: category, UserID: userID}
+			if err := tx.Create(expense).Error; err != nil {
+				return err
+			}
+			split.ExpenseID = &expense.ID
+		}
+		if err := tx.Create(&split).Error; err != nil {
+			return err
+		}
+
+		for _, share := range shares {
+			record := models.SplitShare{
+				SplitID: split.ID,
+				UserID:  userID,
+				Person:  share.Name,
+				Amount:  share.Amount,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &split, expense, nil
+}
+
+// UndoSplitExpense removes a split expense, the shares others owe for it and the payer's expense
+// with its receipts.
+// Repayments someone made towards their share are reversed, newest first, so what they've paid
+// back doesn't end up counting against other debts.
+func UndoSplitExpense(userID string, splitID string) error {
+	splitIDUint, err := strconv.ParseUint(splitID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error converting splitID to uint: %w", err)
+	}
+
+	var receipts []models.ExpenseReceipt
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var split models.SplitExpense
+		if err := tx.Where("id = ? AND user_id = ?", splitIDUint, userID).First(&split).Error; err != nil {
+			return err
+		}
+		var shares []models.SplitShare
+		if err := tx.Where("split_id = ?", split.ID).Find(&shares).Error; err != nil {
+			return err
+		}
+		// A person named twice is reversed once for their total, as their balance counts both
+		var owed []models.SplitShare
+		byPerson := make(map[string]int)
+		for _, share := range shares {
+			key := strings.ToLower(share.Person)
+			if i, seen := byPerson[key]; seen {
+				owed[i].Amount += share.Amount
+				continue
+			}
+			byPerson[key] = len(owed)
+			owed = append(owed, share)
+		}
+		for _, share := range owed {
+			if err := reverseShareRepayments(tx, userID, share); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("split_id = ?", split.ID).Delete(&models.SplitShare{}).Error; err != nil {
+			return err
+		}
+		if split.ExpenseID != nil {
+			if err := tx.Where("id = ? AND user_id = ?", *split.ExpenseID, userID).Delete(&models.ExpensesLog{}).Error; err != nil {
+				return err
+			}
+			if receipts, err = deleteExpenseReceipts(tx, "expense_id = ?", *split.ExpenseID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&split).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteReceiptPhotos(userID, receipts)
+	return nil
+}
+
+// reverseShareRepayments takes back what a person paid towards a share that is being removed,
+// with share holding their total for the split: whatever of it their balance no longer covers.
+// Repayments are reduced or deleted newest first, with older split settle-ups last.
+func reverseShareRepayments(tx *gorm.DB, userID string, share models.SplitShare) error {
+	debts, err := personBalances(tx, userID, share.Person)
+	if err != nil || len(debts) == 0 {
+		return err
+	}
+	remaining := math.Round((share.Amount-math.Max(debts[0].Balance, 0))*100) / 100
+	if remaining <= 0 {
+		return nil
+	}
+
+	var repayments []models.DebtEntry
+	err = tx.Where("user_id = ? AND LOWER(person) = LOWER(?) AND kind = ?", userID, share.Person, models.DebtRepaidToMe).
+		Order("created_at desc, id desc").
+		Find(&repayments).Error
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(repayments) && remaining > 0; i++ {
+		if remaining, err = reduceRepayment(tx, &repayments[i], &repayments[i].Amount, remaining); err != nil {
+			return err
+		}
+	}
+
+	var settlements []models.SplitSettlement
+	err = tx.Where("user_id = ? AND LOWER(person) = LOWER(?)", userID, share.Person).
+		Order("created_at desc, id desc").
+		Find(&settlements).Error
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(settlements) && remaining > 0; i++ {
+		if remaining, err = reduceRepayment(tx, &settlements[i], &settlements[i].Amount, remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reduceRepayment takes up to remaining off a repayment record, deleting it once nothing is
+// left, and returns what is still to be taken back.
+func reduceRepayment(tx *gorm.DB, record interface{}, amount *float64, remaining float64) (float64, error) {
+	if *amount-remaining < 0.005 {
+		return math.Round((remaining-*amount)*100) / 100, tx.Delete(record).Error
+	}
+	*amount = math.Round((*amount-remaining)*100) / 100
+	return 0, tx.Model(record).Update("amount", *amount).Error
+}
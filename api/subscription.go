@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/billing"
+	"quickyexpensetracker/database"
+	"quickyexpensetracker/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoSubscription = errors.New("no subscription")
+	ErrTrialUsed      = errors.New("free trial already used")
+	ErrUnknownPlan    = errors.New("unknown plan")
+)
+
+func GetSubscription(userID string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	result := database.DB.Where("user_id = ?", userID).First(&subscription)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNoSubscription
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &subscription, nil
+}
+
+// HasEntitlement reports whether the user's subscription currently includes the feature.
+func HasEntitlement(userID string, feature billing.Feature) (bool, error) {
+	subscription, err := GetSubscription(userID)
+	if errors.Is(err, ErrNoSubscription) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return billing.Entitled(subscription, feature, time.Now()), nil
+}
+
+// StartTrial puts the user on a plan's free trial. Each user gets one trial, so it's refused
+// once the user has had any subscription.
+func StartTrial(userID string, planKey string, now time.Time) (*models.Subscription, error) {
+	plan, ok := billing.GetPlan(planKey)
+	if !ok {
+		return nil, ErrUnknownPlan
+	}
+	if _, err := GetSubscription(userID); err == nil {
+		return nil, ErrTrialUsed
+	} else if !errors.Is(err, ErrNoSubscription) {
+		return nil, err
+	}
+
+	subscription := billing.NewTrial(userID, plan, now)
+	if err := database.DB.Create(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Subscribe charges the user for one period of the plan through the payment provider and
+// extends their subscription by it. This is also how a subscription is renewed or switched
+// to another plan; time left on the trial or the current period is kept.
+func Subscribe(userID string, planKey string, now time.Time) (*models.Subscription, *models.SubscriptionPayment, error) {
+	plan, ok := billing.GetPlan(planKey)
+	if !ok {
+		return nil, nil, ErrUnknownPlan
+	}
+
+	subscription, err := GetSubscription(userID)
+	if errors.Is(err, ErrNoSubscription) {
+		subscription = &models.Subscription{UserID: userID}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	payment, err := billing.Renew(billing.Provider, subscription, plan, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(subscription).Error; err != nil {
+			return err
+		}
+		payment.SubscriptionID = subscription.ID
+		return tx.Create(payment).Error
+	})
+	if err != nil {
+		// The charge went through, so leave a trail for refunding it by hand
+		fmt.Printf("Error saving subscription for user %s after charge %s: %v\n", userID, payment.ProviderReference, err)
+		return nil, nil, err
+	}
+	return subscription, payment, nil
+}
+
+// CancelSubscription stops a live subscription from being renewed. The user keeps the plan's
+// features until the end of the period already paid for.
+func CancelSubscription(userID string) (*models.Subscription, error) {
+	subscription, err := GetSubscription(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !billing.IsLive(subscription, time.Now()) {
+		return nil, ErrNoSubscription
+	}
+
+	subscription.CancelAtPeriodEnd = true
+	if err := database.DB.Model(subscription).Update("cancel_at_period_end", true).Error; err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetSubscriptionsDueForRenewalReminder returns live subscriptions ending within the window
+// that haven't been reminded about this period. Cancelled ones are left to lapse quietly.
+func GetSubscriptionsDueForRenewalReminder(now time.Time, window time.Duration) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	result := database.DB.
+		Where("status IN ? AND cancel_at_period_end = ? AND renewal_reminder_sent_at IS NULL", billing.LiveStatuses(), false).
+		Where("current_period_end > ? AND current_period_end <= ?", now, now.Add(window)).
+		Find(&subscriptions)
+	return subscriptions, result.Error
+}
+
+func MarkRenewalReminderSent(subscription *models.Subscription, sentAt time.Time) error {
+	subscription.RenewalReminderSentAt = &sentAt
+	return database.DB.Model(subscription).Update("renewal_reminder_sent_at", sentAt).Error
+}
+
+// ExpireSubscriptions marks live subscriptions whose period has ended as expired and returns
+// them as they were, so callers can tell an ended trial from an ended paid period. The update
+// is conditional on the status, so each one is expired and reported once.
+func ExpireSubscriptions(now time.Time) ([]models.Subscription, error) {
+	var candidates []models.Subscription
+	result := database.DB.
+		Where("status IN ? AND current_period_end <= ?", billing.LiveStatuses(), now).
+		Find(&candidates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var expired []models.Subscription
+	for _, subscription := range candidates {
+		result := database.DB.Model(&models.Subscription{}).
+			Where("id = ? AND status = ?", subscription.ID, subscription.Status).
+			Update("status", models.SubscriptionExpired)
+		if result.Error != nil {
+			return expired, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		expired = append(expired, subscription)
+	}
+	return expired, nil
+}
@@ -0,0 +1,192 @@
+package billing
+
+import (
+	"errors"
+	"quickyexpensetracker/models"
+	"testing"
+	"time"
+)
+
+func TestLocalProviderCharge(t *testing.T) {
+	provider := NewLocalProvider()
+
+	charge, err := provider.Charge(ChargeRequest{UserID: "123", Amount: 99, Description: "Premium Monthly"})
+	if err != nil {
+		t.Fatalf("Charge returned error: %v", err)
+	}
+	if charge.Reference != "local_1" || charge.Amount != 99 {
+		t.Fatalf("Charge = %+v, want reference local_1 for 99", charge)
+	}
+
+	provider.SetDecline(true)
+	if _, err := provider.Charge(ChargeRequest{UserID: "123", Amount: 99}); !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("declined Charge error = %v, want ErrPaymentDeclined", err)
+	}
+	if _, err := provider.Charge(ChargeRequest{UserID: "123", Amount: 0}); err == nil {
+		t.Fatal("Charge accepted a zero amount")
+	}
+
+	if got := len(provider.Charges()); got != 1 {
+		t.Fatalf("len(Charges()) = %d, want 1", got)
+	}
+}
+
+func TestNextPeriodEnd(t *testing.T) {
+	plan, ok := GetPlan("PREMIUM_MONTHLY")
+	if !ok {
+		t.Fatal("GetPlan(PREMIUM_MONTHLY) not found")
+	}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	if got, want := plan.NextPeriodEnd(nil, now), time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextPeriodEnd(nil) = %v, want %v", got, want)
+	}
+
+	// Renewing early adds the new period after the one already paid for
+	current := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	if got, want := plan.NextPeriodEnd(&current, now), time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextPeriodEnd(active) = %v, want %v", got, want)
+	}
+
+	lapsed := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	if got, want := plan.NextPeriodEnd(&lapsed, now), time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextPeriodEnd(lapsed) = %v, want %v", got, want)
+	}
+}
+
+func TestPlanIncludes(t *testing.T) {
+	for _, plan := range Plans {
+		if !plan.Includes(FeatureExports) {
+			t.Errorf("plan %s does not include exports", plan.Key)
+		}
+	}
+	if (Plan{}).Includes(FeatureExports) {
+		t.Error("empty plan includes exports")
+	}
+}
+
+func TestTrialLifecycle(t *testing.T) {
+	plan, _ := GetPlan("premium_monthly")
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	trial := NewTrial("123", plan, now)
+	if trial.Status != models.SubscriptionTrialing {
+		t.Fatalf("trial status = %s, want trialing", trial.Status)
+	}
+	if want := now.AddDate(0, 0, plan.TrialDays); !trial.CurrentPeriodEnd.Equal(want) {
+		t.Fatalf("trial ends %v, want %v", trial.CurrentPeriodEnd, want)
+	}
+	if !Entitled(&trial, FeatureExports, now) {
+		t.Error("trial is not entitled to exports")
+	}
+	if Entitled(&trial, FeatureExports, trial.CurrentPeriodEnd) {
+		t.Error("trial is still entitled to exports when it ends")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	monthly, _ := GetPlan("premium_monthly")
+	yearly, _ := GetPlan("premium_yearly")
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	reminded := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name         string
+		subscription models.Subscription
+		plan         Plan
+		wantEnd      time.Time
+	}{
+		{
+			name:         "new subscriber",
+			subscription: models.Subscription{UserID: "123"},
+			plan:         monthly,
+			wantEnd:      time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "during a trial keeps the trial days",
+			subscription: NewTrial("123", monthly, now.AddDate(0, 0, -2)),
+			plan:         monthly,
+			wantEnd:      time.Date(2025, 4, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "early renewal after a cancel",
+			subscription: models.Subscription{
+				UserID: "123", PlanKey: monthly.Key, Status: models.SubscriptionActive,
+				CurrentPeriodEnd: time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC), CancelAtPeriodEnd: true, RenewalReminderSentAt: &reminded,
+			},
+			plan:    yearly,
+			wantEnd: time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "after expiry starts over",
+			subscription: models.Subscription{
+				UserID: "123", PlanKey: monthly.Key, Status: models.SubscriptionExpired,
+				CurrentPeriodEnd: time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC),
+			},
+			plan:    monthly,
+			wantEnd: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		provider := NewLocalProvider()
+		subscription := tt.subscription
+
+		payment, err := Renew(provider, &subscription, tt.plan, now)
+		if err != nil {
+			t.Fatalf("%s: Renew returned error: %v", tt.name, err)
+		}
+		if subscription.Status != models.SubscriptionActive || subscription.PlanKey != tt.plan.Key {
+			t.Errorf("%s: subscription is %s on %s, want active on %s", tt.name, subscription.Status, subscription.PlanKey, tt.plan.Key)
+		}
+		if !subscription.CurrentPeriodEnd.Equal(tt.wantEnd) || !payment.PeriodEnd.Equal(tt.wantEnd) {
+			t.Errorf("%s: period ends %v (payment %v), want %v", tt.name, subscription.CurrentPeriodEnd, payment.PeriodEnd, tt.wantEnd)
+		}
+		if subscription.CancelAtPeriodEnd || subscription.RenewalReminderSentAt != nil {
+			t.Errorf("%s: cancel and renewal reminder were not reset", tt.name)
+		}
+		charges := provider.Charges()
+		if len(charges) != 1 || charges[0].Amount != tt.plan.Price || payment.ProviderReference != charges[0].Reference {
+			t.Errorf("%s: charges = %+v, payment = %+v, want one charge of %.2f", tt.name, charges, payment, tt.plan.Price)
+		}
+	}
+}
+
+func TestRenewDeclined(t *testing.T) {
+	plan, _ := GetPlan("premium_monthly")
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	provider := NewLocalProvider()
+	provider.SetDecline(true)
+
+	subscription := NewTrial("123", plan, now)
+	before := subscription
+	if _, err := Renew(provider, &subscription, plan, now); !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("Renew error = %v, want ErrPaymentDeclined", err)
+	}
+	if subscription != before {
+		t.Errorf("declined Renew changed the subscription to %+v", subscription)
+	}
+}
+
+func TestIsLive(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status models.SubscriptionStatus
+		end    time.Time
+		want   bool
+	}{
+		{models.SubscriptionActive, now.Add(time.Hour), true},
+		{models.SubscriptionTrialing, now.Add(time.Hour), true},
+		{models.SubscriptionActive, now, false},
+		{models.SubscriptionExpired, now.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		subscription := models.Subscription{Status: tt.status, CurrentPeriodEnd: tt.end}
+		if got := IsLive(&subscription, now); got != tt.want {
+			t.Errorf("IsLive(%s ending %v) = %v, want %v", tt.status, tt.end, got, tt.want)
+		}
+	}
+	if IsLive(nil, now) {
+		t.Error("IsLive(nil) = true")
+	}
+}
@@ -0,0 +1,53 @@
+package billing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LocalProvider is a stand-in payment provider for development and tests. It keeps charges in
+// memory and approves them unless told to decline.
+type LocalProvider struct {
+	mu      sync.Mutex
+	charges []Charge
+	decline bool
+}
+
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+func (p *LocalProvider) Charge(req ChargeRequest) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("charge amount must be greater than zero")
+	}
+	if p.decline {
+		return nil, ErrPaymentDeclined
+	}
+
+	charge := Charge{
+		Reference: fmt.Sprintf("local_%d", len(p.charges)+1),
+		Amount:    req.Amount,
+		ChargedAt: time.Now(),
+	}
+	p.charges = append(p.charges, charge)
+	return &charge, nil
+}
+
+// SetDecline makes later charges fail with ErrPaymentDeclined, or succeed again.
+func (p *LocalProvider) SetDecline(decline bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.decline = decline
+}
+
+// Charges returns the charges taken so far.
+func (p *LocalProvider) Charges() []Charge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Charge(nil), p.charges...)
+}
@@ -0,0 +1,76 @@
+package billing
+
+import (
+	"strings"
+	"time"
+)
+
+// Feature is a premium capability that a plan entitles its subscribers to.
+type Feature string
+
+const (
+	FeatureExports Feature = "exports" // Downloading expenses as a CSV file
+)
+
+// Plan is a subscription the user can buy. Prices are in pesos per billing period.
+type Plan struct {
+	Key          string
+	Name         string
+	Price        float64
+	PeriodMonths int
+	TrialDays    int
+	Features     []Feature
+}
+
+var premiumFeatures = []Feature{FeatureExports}
+
+var Plans = []Plan{
+	{Key: "premium_monthly", Name: "Premium Monthly", Price: 99, PeriodMonths: 1, TrialDays: 7, Features: premiumFeatures},
+	{Key: "premium_yearly", Name: "Premium Yearly", Price: 990, PeriodMonths: 12, TrialDays: 7, Features: premiumFeatures},
+}
+
+// GetPlan looks up a plan by key, ignoring case since Messenger payloads arrive uppercased.
+func GetPlan(key string) (Plan, bool) {
+	for _, plan := range Plans {
+		if strings.EqualFold(plan.Key, key) {
+			return plan, true
+		}
+	}
+	return Plan{}, false
+}
+
+func (p Plan) Includes(feature Feature) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// PeriodLabel describes the billing period, e.g. "month" or "year".
+func (p Plan) PeriodLabel() string {
+	switch p.PeriodMonths {
+	case 1:
+		return "month"
+	case 12:
+		return "year"
+	default:
+		return "billing period"
+	}
+}
+
+// NextPeriodEnd returns when a period paid for at now would end. Paying before the current
+// period is over extends it rather than cutting it short.
+func (p Plan) NextPeriodEnd(currentEnd *time.Time, now time.Time) time.Time {
+	start := now
+	if currentEnd != nil && currentEnd.After(now) {
+		start = *currentEnd
+	}
+	return start.AddDate(0, p.PeriodMonths, 0)
+}
+
+// TrialEnd returns when a trial started at now would end.
+func (p Plan) TrialEnd(now time.Time) time.Time {
+	return now.AddDate(0, 0, p.TrialDays)
+}
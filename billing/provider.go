@@ -0,0 +1,50 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+var ErrPaymentDeclined = errors.New("payment declined")
+
+type ChargeRequest struct {
+	UserID      string
+	Amount      float64
+	Description string
+}
+
+// Charge is a successful payment as reported by the provider.
+type Charge struct {
+	Reference string
+	Amount    float64
+	ChargedAt time.Time
+}
+
+// PaymentProvider takes payments for subscriptions. Implementations wrap a payment gateway;
+// the rest of the app only sees the charge reference.
+type PaymentProvider interface {
+	Charge(req ChargeRequest) (*Charge, error)
+}
+
+var Provider PaymentProvider
+
+// InitPaymentProvider sets up the payment provider named by PAYMENT_PROVIDER. Only the local
+// stand-in is available, which approves every charge without moving money.
+func InitPaymentProvider() {
+	name := os.Getenv("PAYMENT_PROVIDER")
+	if name == "" {
+		name = "local"
+	}
+
+	switch name {
+	case "local":
+		Provider = NewLocalProvider()
+	default:
+		log.Fatalf("Unknown payment provider: %s", name)
+	}
+
+	fmt.Printf("Payment provider ready: %s\n", name)
+}
@@ -0,0 +1,83 @@
+package billing
+
+import (
+	"fmt"
+	"quickyexpensetracker/models"
+	"time"
+)
+
+// liveStatuses are the statuses that grant a plan's features until the period ends.
+var liveStatuses = []models.SubscriptionStatus{models.SubscriptionTrialing, models.SubscriptionActive}
+
+// LiveStatuses returns the subscription statuses that can still grant a plan's features.
+func LiveStatuses() []models.SubscriptionStatus {
+	return append([]models.SubscriptionStatus(nil), liveStatuses...)
+}
+
+// IsLive reports whether the subscription still grants its plan's features at now. Expiry is
+// recorded on a ticker, so the period end is checked here as well.
+func IsLive(subscription *models.Subscription, now time.Time) bool {
+	if subscription == nil || !now.Before(subscription.CurrentPeriodEnd) {
+		return false
+	}
+	for _, status := range liveStatuses {
+		if subscription.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Entitled reports whether the subscription includes the feature at now.
+func Entitled(subscription *models.Subscription, feature Feature, now time.Time) bool {
+	if !IsLive(subscription, now) {
+		return false
+	}
+	plan, ok := GetPlan(subscription.PlanKey)
+	return ok && plan.Includes(feature)
+}
+
+// NewTrial returns a trialing subscription to the plan for a user who hasn't subscribed before.
+func NewTrial(userID string, plan Plan, now time.Time) models.Subscription {
+	return models.Subscription{
+		UserID:           userID,
+		PlanKey:          plan.Key,
+		Status:           models.SubscriptionTrialing,
+		CurrentPeriodEnd: plan.TrialEnd(now),
+	}
+}
+
+// Renew charges one period of the plan through the provider and, once paid, extends the
+// subscription by it. Time left on a live trial or period is kept; a lapsed subscription starts
+// over from now. The subscription is left as it was when the charge fails.
+func Renew(provider PaymentProvider, subscription *models.Subscription, plan Plan, now time.Time) (*models.SubscriptionPayment, error) {
+	var currentEnd *time.Time
+	if IsLive(subscription, now) {
+		currentEnd = &subscription.CurrentPeriodEnd
+	}
+	periodEnd := plan.NextPeriodEnd(currentEnd, now)
+
+	charge, err := provider.Charge(ChargeRequest{
+		UserID:      subscription.UserID,
+		Amount:      plan.Price,
+		Description: fmt.Sprintf("%s until %s", plan.Name, periodEnd.Format("Jan 2, 2006")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.PlanKey = plan.Key
+	subscription.Status = models.SubscriptionActive
+	subscription.CurrentPeriodEnd = periodEnd
+	subscription.CancelAtPeriodEnd = false
+	subscription.RenewalReminderSentAt = nil
+
+	return &models.SubscriptionPayment{
+		SubscriptionID:    subscription.ID,
+		UserID:            subscription.UserID,
+		PlanKey:           plan.Key,
+		Amount:            charge.Amount,
+		ProviderReference: charge.Reference,
+		PeriodEnd:         periodEnd,
+	}, nil
+}
@@ -21,7 +21,7 @@ func InitDB() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	err = DB.AutoMigrate(&models.ExpensesLog{}, &models.RemindersLog{})
+	err = DB.AutoMigrate(&models.ExpensesLog{}, &models.RemindersLog{}, &models.UserPreference{}, &models.SavingsGoal{}, &models.GoalContribution{}, &models.ReminderNotification{}, &models.ReminderSnooze{}, &models.ReminderOccurrence{}, &models.ReminderStatusChange{}, &models.Payee{}, &models.PaymentProof{}, &models.SplitExpense{}, &models.SplitShare{}, &models.SplitSettlement{}, &models.Household{}, &models.HouseholdMember{}, &models.DebtEntry{}, &models.RecurringExpense{}, &models.Subscription{}, &models.SubscriptionPayment{}, &models.ExpenseReceipt{})
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
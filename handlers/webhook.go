@@ -15,10 +15,11 @@ import (
 )
 
 type UserInstance struct {
-	PSID    string
-	Command string
-	MID     string
-	Source  string
+	PSID        string
+	Command     string
+	MID         string
+	Source      string
+	Attachments []string // URLs of images the user sent
 }
 
 func HandleVerification(c *gin.Context) {
@@ -131,7 +132,8 @@ func HandleWebhook(c *gin.Context) {
 				}
 				mid, _ = message["mid"].(string)
 
-				if text, ok := message["text"].(string); ok {
+				// Quick replies also carry the button title as text; keep them as payloads.
+				if text, ok := message["text"].(string); ok && command == "" {
 					fmt.Printf("Received message from PSID %s: %s\n", psid, text)
 					source = "MESSAGE"
 				}
@@ -154,6 +156,17 @@ func HandleWebhook(c *gin.Context) {
 			}
 
 			if command == "" {
+				if message, ok := msgMap["message"].(map[string]interface{}); ok {
+					user.Attachments = imageAttachmentURLs(message)
+					if len(user.Attachments) > 0 {
+						mid, _ = message["mid"].(string)
+						source = "ATTACHMENT"
+						fmt.Printf("Received %d image(s) from PSID %s\n", len(user.Attachments), psid)
+					}
+				}
+			}
+
+			if command == "" && source != "ATTACHMENT" {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid command"})
 				continue
 			}
@@ -179,6 +192,33 @@ func HandleWebhook(c *gin.Context) {
 			services.ProcessMainCommand(user.Command, user.PSID, user.MID, pageAccessToken)
 		} else if user.Source == "MESSAGE" {
 			services.ProcessTextMessageReceived(user.Command, user.PSID, user.MID, pageAccessToken)
+		} else if user.Source == "ATTACHMENT" {
+			services.ProcessAttachmentsReceived(user.Attachments, user.PSID, user.MID, pageAccessToken)
+		}
+	}
+}
+
+// imageAttachmentURLs returns the URLs of the images in a message. Stickers, including the
+// like button, arrive as images too and are left out.
+func imageAttachmentURLs(message map[string]interface{}) []string {
+	attachments, ok := message["attachments"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var urls []string
+	for _, attachment := range attachments {
+		attachmentMap, ok := attachment.(map[string]interface{})
+		if !ok || attachmentMap["type"] != "image" {
+			continue
+		}
+		payload, ok := attachmentMap["payload"].(map[string]interface{})
+		if !ok || payload["sticker_id"] != nil {
+			continue
+		}
+		if url, ok := payload["url"].(string); ok && url != "" {
+			urls = append(urls, url)
 		}
 	}
+	return urls
 }
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"log"
 	"time" // Added for ticker
+	_ "time/tzdata"
 
+	"quickyexpensetracker/billing"
 	"quickyexpensetracker/database"
 	"quickyexpensetracker/handlers"
 	"quickyexpensetracker/services" // Added for reminder processor
+	"quickyexpensetracker/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -19,12 +22,16 @@ func main() {
 		log.Fatalf("Err loading .env file: %v", err)
 	}
 	database.InitDB()
+	storage.InitBlobStore()
+	billing.InitPaymentProvider()
 
-	// Start the reminder processor
+	// Start the reminder processor, the recurring expense scheduler and subscription checks
 	go func() {
 		// Run once immediately at startup, then tick.
 		fmt.Println("Starting initial check for due reminders...")
 		services.CheckDueReminders()
+		services.LogDueRecurringExpenses()
+		services.CheckSubscriptions()
 
 		// Then, check periodically.
 		// For example, check every 1 hour. Adjust the duration as needed.
@@ -34,6 +41,8 @@ func main() {
 		for range ticker.C {
 			fmt.Println("Periodic check for due reminders triggered by ticker...")
 			services.CheckDueReminders()
+			services.LogDueRecurringExpenses()
+			services.CheckSubscriptions()
 		}
 	}()
 
@@ -0,0 +1,4 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_preferences DROP COLUMN anomaly_sensitivity;
+-- +goose StatementEnd
@@ -0,0 +1,5 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_preferences
+    ADD COLUMN anomaly_sensitivity VARCHAR(20) NOT NULL DEFAULT 'medium';
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS goal_contributions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS savings_goals;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS savings_goals (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    name LONGTEXT NOT NULL,
+    target_amount DOUBLE NOT NULL,
+    saved_amount DOUBLE NOT NULL DEFAULT 0,
+    target_date DATETIME(3) NULL,
+    INDEX idx_savings_goals_user_id (user_id),
+    INDEX idx_savings_goals_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS goal_contributions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    goal_id BIGINT UNSIGNED NOT NULL,
+    user_id LONGTEXT NOT NULL,
+    amount DOUBLE NOT NULL,
+    INDEX idx_goal_contributions_goal_id (goal_id),
+    INDEX idx_goal_contributions_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
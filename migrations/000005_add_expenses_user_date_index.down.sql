@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_expenses_logs_user_id_created_at ON expenses_logs;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses_logs MODIFY user_id LONGTEXT;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses_logs MODIFY user_id VARCHAR(191);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_expenses_logs_user_id_created_at ON expenses_logs (user_id, created_at);
+-- +goose StatementEnd
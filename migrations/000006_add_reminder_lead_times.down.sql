@@ -0,0 +1,12 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_notifications;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE user_preferences DROP COLUMN reminder_lead_days;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN lead_days;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN lead_days LONGTEXT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE user_preferences
+    ADD COLUMN reminder_lead_days VARCHAR(50) NOT NULL DEFAULT '3,1';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminder_notifications (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    reminder_id BIGINT UNSIGNED NOT NULL,
+    due_date DATETIME(3) NOT NULL,
+    lead_days BIGINT NOT NULL,
+    UNIQUE INDEX idx_reminder_stage (reminder_id, due_date, lead_days),
+    INDEX idx_reminder_notifications_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
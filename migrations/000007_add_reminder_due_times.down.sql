@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_preferences DROP COLUMN timezone, DROP COLUMN reminder_time;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN due_time_set;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN due_time_set BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE user_preferences
+    ADD COLUMN reminder_time VARCHAR(5) NOT NULL DEFAULT '09:00',
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Manila';
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_snoozes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN snooze_count, DROP COLUMN snoozed_until;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs
+    ADD COLUMN snoozed_until DATETIME(3) NULL,
+    ADD COLUMN snooze_count BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminder_snoozes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    reminder_id BIGINT UNSIGNED NOT NULL,
+    due_date DATETIME(3) NOT NULL,
+    `option` LONGTEXT NOT NULL,
+    snoozed_until DATETIME(3) NOT NULL,
+    INDEX idx_reminder_snoozes_reminder_id (reminder_id),
+    INDEX idx_reminder_snoozes_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
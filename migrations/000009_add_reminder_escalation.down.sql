@@ -0,0 +1,4 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN last_escalated_at, DROP COLUMN escalation_count;
+-- +goose StatementEnd
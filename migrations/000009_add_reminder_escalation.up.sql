@@ -0,0 +1,6 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs
+    ADD COLUMN escalation_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN last_escalated_at DATETIME(3) NULL;
+-- +goose StatementEnd
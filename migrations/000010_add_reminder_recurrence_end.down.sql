@@ -0,0 +1,4 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN recurrence_end;
+-- +goose StatementEnd
@@ -0,0 +1,4 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN recurrence_end DATETIME(3) NULL;
+-- +goose StatementEnd
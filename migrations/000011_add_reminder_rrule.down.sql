@@ -0,0 +1,14 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN recurrence_end DATETIME(3) NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs
+SET recurrence_end = STR_TO_DATE(SUBSTRING_INDEX(rrule, 'UNTIL=', -1), '%Y%m%dT%H%i%s')
+WHERE rrule LIKE '%UNTIL=%';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN recurrence_start, DROP COLUMN rrule;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reminders_logs
+    ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN recurrence_start DATETIME(3) NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs
+SET rrule = CONCAT(
+        'FREQ=', UPPER(frequency),
+        IF(recurrence_end IS NULL, '', CONCAT(';UNTIL=', DATE_FORMAT(recurrence_end, '%Y%m%dT%H%i%s')))
+    ),
+    recurrence_start = due_date
+WHERE frequency IN ('daily', 'weekly', 'monthly');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN recurrence_end;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminder_occurrences (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    reminder_id BIGINT UNSIGNED NOT NULL,
+    user_id VARCHAR(191) NOT NULL,
+    due_date DATETIME(3) NOT NULL,
+    notified BOOLEAN NOT NULL DEFAULT FALSE,
+    notified_at DATETIME(3) NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    paid_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_reminder_occurrence (reminder_id, due_date),
+    INDEX idx_reminder_occurrences_user_id (user_id),
+    INDEX idx_reminder_occurrences_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Backfill one occurrence for every reminder that was already notified or paid.
+INSERT INTO reminder_occurrences (created_at, updated_at, reminder_id, user_id, due_date, notified, status, paid_at)
+SELECT NOW(3), NOW(3), id, user_id, due_date, notified,
+       IF(status IN ('paid', 'completed'), 'paid', 'pending'),
+       IF(status IN ('paid', 'completed'), updated_at, NULL)
+FROM reminders_logs
+WHERE deleted_at IS NULL AND (notified = TRUE OR status IN ('paid', 'completed'));
+-- +goose StatementEnd
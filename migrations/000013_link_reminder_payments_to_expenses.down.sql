@@ -0,0 +1,12 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminder_occurrences DROP COLUMN expense_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN category;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses_logs DROP COLUMN reminder_occurrence_id, DROP COLUMN reminder_id;
+-- +goose StatementEnd
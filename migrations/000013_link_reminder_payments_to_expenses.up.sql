@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE expenses_logs
+    ADD COLUMN reminder_id BIGINT UNSIGNED NULL,
+    ADD COLUMN reminder_occurrence_id BIGINT UNSIGNED NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN category LONGTEXT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminder_occurrences ADD COLUMN expense_id BIGINT UNSIGNED NULL;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_status_changes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN status_changed_at;
+-- +goose StatementEnd
+
+-- Statuses normalized by the up migration are left as they are.
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- "completed" was never written by the bot but was what the accomplished view queried for.
+UPDATE reminders_logs SET status = 'paid' WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs SET status = 'pending'
+WHERE status IS NULL OR status NOT IN ('pending', 'overdue', 'paid', 'cancelled', 'skipped');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs ADD COLUMN status_changed_at DATETIME(3) NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs SET status_changed_at = updated_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminder_status_changes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    reminder_id BIGINT UNSIGNED NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    changed_at DATETIME(3) NOT NULL,
+    INDEX idx_reminder_status_changes_reminder_id (reminder_id),
+    INDEX idx_reminder_status_changes_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP COLUMN transfer_rail, DROP COLUMN bank_name;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs RENAME COLUMN account_number TO gcash_number;
+-- +goose StatementEnd
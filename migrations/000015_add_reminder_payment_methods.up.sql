@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The number column now holds the account for whichever payment method the reminder uses.
+ALTER TABLE reminders_logs RENAME COLUMN gcash_number TO account_number;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs
+    ADD COLUMN bank_name LONGTEXT NULL,
+    ADD COLUMN transfer_rail LONGTEXT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs SET payment_method = 'Gcash' WHERE payment_method IS NULL OR payment_method = '';
+-- +goose StatementEnd
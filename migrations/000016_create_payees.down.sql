@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reminders_logs DROP INDEX idx_reminders_logs_payee_id, DROP COLUMN payee_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payees;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payees (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    name VARCHAR(191) NOT NULL,
+    payment_method LONGTEXT NULL,
+    account_number LONGTEXT NULL,
+    bank_name LONGTEXT NULL,
+    transfer_rail LONGTEXT NULL,
+    default_amount DOUBLE NULL,
+    UNIQUE INDEX idx_payee_user_name (user_id, name),
+    INDEX idx_payees_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE reminders_logs
+    ADD COLUMN payee_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_reminders_logs_payee_id (payee_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- One payee per recipient name, using the details of that recipient's most recent reminder.
+INSERT INTO payees (created_at, updated_at, user_id, name, payment_method, account_number, bank_name, transfer_rail)
+SELECT NOW(3), NOW(3), r.user_id, r.recipient, r.payment_method, r.account_number, r.bank_name, r.transfer_rail
+FROM reminders_logs r
+JOIN (
+    SELECT MAX(id) AS id
+    FROM reminders_logs
+    WHERE deleted_at IS NULL AND reminder_type = 'payment' AND recipient IS NOT NULL AND recipient <> ''
+    GROUP BY user_id, LOWER(recipient)
+) latest ON latest.id = r.id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE reminders_logs r
+JOIN payees p ON p.user_id = r.user_id AND LOWER(p.name) = LOWER(r.recipient)
+SET r.payee_id = p.id
+WHERE r.deleted_at IS NULL AND r.reminder_type = 'payment';
+-- +goose StatementEnd
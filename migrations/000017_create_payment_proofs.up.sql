@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS payment_proofs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    reminder_id BIGINT UNSIGNED NULL,
+    occurrence_id BIGINT UNSIGNED NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    INDEX idx_payment_proofs_user_id (user_id),
+    INDEX idx_payment_proofs_reminder_id (reminder_id),
+    INDEX idx_payment_proofs_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
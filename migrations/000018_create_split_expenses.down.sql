@@ -0,0 +1,12 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS split_settlements;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS split_shares;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS split_expenses;
+-- +goose StatementEnd
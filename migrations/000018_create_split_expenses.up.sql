@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS split_expenses (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    expense_id BIGINT UNSIGNED NULL,
+    category LONGTEXT NULL,
+    total_amount DOUBLE NOT NULL,
+    INDEX idx_split_expenses_user_id (user_id),
+    INDEX idx_split_expenses_expense_id (expense_id),
+    INDEX idx_split_expenses_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS split_shares (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    split_id BIGINT UNSIGNED NOT NULL,
+    user_id VARCHAR(191) NOT NULL,
+    person LONGTEXT NOT NULL,
+    amount DOUBLE NOT NULL,
+    INDEX idx_split_shares_split_id (split_id),
+    INDEX idx_split_shares_user_id (user_id),
+    INDEX idx_split_shares_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS split_settlements (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    person LONGTEXT NOT NULL,
+    amount DOUBLE NOT NULL,
+    settled_at DATETIME(3) NOT NULL,
+    INDEX idx_split_settlements_user_id (user_id),
+    INDEX idx_split_settlements_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
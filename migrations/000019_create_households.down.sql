@@ -0,0 +1,12 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses_logs DROP INDEX idx_expenses_logs_household_id, DROP COLUMN household_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS household_members;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS households;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS households (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    name LONGTEXT NOT NULL,
+    invite_code VARCHAR(16) NOT NULL,
+    owner_id LONGTEXT NOT NULL,
+    UNIQUE INDEX idx_households_invite_code (invite_code),
+    INDEX idx_households_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS household_members (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    household_id BIGINT UNSIGNED NOT NULL,
+    user_id VARCHAR(191) NOT NULL,
+    display_name LONGTEXT NULL,
+    UNIQUE INDEX idx_household_members_user_id (user_id),
+    INDEX idx_household_members_household_id (household_id),
+    INDEX idx_household_members_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses_logs
+    ADD COLUMN household_id BIGINT UNSIGNED NULL,
+    ADD INDEX idx_expenses_logs_household_id (household_id);
+-- +goose StatementEnd
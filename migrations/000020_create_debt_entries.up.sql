@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS debt_entries (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    person LONGTEXT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    amount DOUBLE NOT NULL,
+    due_date DATETIME(3) NULL,
+    reminder_id BIGINT UNSIGNED NULL,
+    INDEX idx_debt_entries_user_id (user_id),
+    INDEX idx_debt_entries_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses_logs DROP COLUMN recurring_expense_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS recurring_expenses;
+-- +goose StatementEnd
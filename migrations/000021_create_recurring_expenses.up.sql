@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS recurring_expenses (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    amount DOUBLE NOT NULL,
+    category LONGTEXT NOT NULL,
+    rrule LONGTEXT NOT NULL,
+    start_date DATETIME(3) NOT NULL,
+    next_run_at DATETIME(3) NULL,
+    last_logged_at DATETIME(3) NULL,
+    INDEX idx_recurring_expenses_user_id (user_id),
+    INDEX idx_recurring_expenses_next_run_at (next_run_at),
+    INDEX idx_recurring_expenses_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE expenses_logs ADD COLUMN recurring_expense_id BIGINT UNSIGNED NULL;
+-- +goose StatementEnd
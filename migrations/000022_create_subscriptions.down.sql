@@ -0,0 +1,8 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_payments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    plan_key LONGTEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    current_period_end DATETIME(3) NOT NULL,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    renewal_reminder_sent_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_subscriptions_user_id (user_id),
+    INDEX idx_subscriptions_current_period_end (current_period_end),
+    INDEX idx_subscriptions_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS subscription_payments (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    subscription_id BIGINT UNSIGNED NOT NULL,
+    user_id VARCHAR(191) NOT NULL,
+    plan_key LONGTEXT NOT NULL,
+    amount DOUBLE NOT NULL,
+    provider_reference LONGTEXT NOT NULL,
+    period_end DATETIME(3) NOT NULL,
+    INDEX idx_subscription_payments_subscription_id (subscription_id),
+    INDEX idx_subscription_payments_user_id (user_id),
+    INDEX idx_subscription_payments_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
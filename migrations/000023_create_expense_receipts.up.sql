@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS expense_receipts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    user_id VARCHAR(191) NOT NULL,
+    expense_id BIGINT UNSIGNED NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    INDEX idx_expense_receipts_user_id (user_id),
+    INDEX idx_expense_receipts_expense_id (expense_id),
+    INDEX idx_expense_receipts_deleted_at (deleted_at)
+);
+-- +goose StatementEnd
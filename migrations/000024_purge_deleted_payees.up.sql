@@ -0,0 +1,5 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Payees used to be soft-deleted, which kept their names taken in idx_payee_user_name
+DELETE FROM payees WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
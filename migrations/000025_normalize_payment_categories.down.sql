@@ -0,0 +1,5 @@
+-- +goose Down
+-- +goose StatementBegin
+-- The original casing isn't kept, so there is nothing to restore
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Categories picked for paid reminders were saved as uppercased payloads, e.g. "BILLS"
+UPDATE reminders_logs
+SET category = CASE UPPER(category)
+    WHEN 'BILLS' THEN 'Bills'
+    WHEN 'RENT' THEN 'Rent'
+    WHEN 'UTILITIES' THEN 'Utilities'
+    WHEN 'LOANS' THEN 'Loans'
+    WHEN 'SUBSCRIPTIONS' THEN 'Subscriptions'
+    WHEN 'OTHER' THEN 'Other'
+END
+WHERE UPPER(category) IN ('BILLS', 'RENT', 'UTILITIES', 'LOANS', 'SUBSCRIPTIONS', 'OTHER');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE expenses_logs
+SET category = CASE UPPER(category)
+    WHEN 'BILLS' THEN 'Bills'
+    WHEN 'RENT' THEN 'Rent'
+    WHEN 'UTILITIES' THEN 'Utilities'
+    WHEN 'LOANS' THEN 'Loans'
+    WHEN 'SUBSCRIPTIONS' THEN 'Subscriptions'
+    WHEN 'OTHER' THEN 'Other'
+END
+WHERE reminder_occurrence_id IS NOT NULL
+  AND UPPER(category) IN ('BILLS', 'RENT', 'UTILITIES', 'LOANS', 'SUBSCRIPTIONS', 'OTHER');
+-- +goose StatementEnd
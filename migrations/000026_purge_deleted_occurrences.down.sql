@@ -0,0 +1,5 @@
+-- +goose Down
+-- +goose StatementBegin
+-- Purged occurrences can't be restored
+SELECT 1;
+-- +goose StatementEnd
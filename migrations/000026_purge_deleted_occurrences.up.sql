@@ -0,0 +1,6 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Undone payments used to soft-delete their occurrence, which kept the due date taken in
+-- idx_reminder_occurrence
+DELETE FROM reminder_occurrences WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
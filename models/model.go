@@ -6,23 +6,275 @@ import (
 	"gorm.io/gorm"
 )
 
+// ExpensesLog spells out gorm.Model's fields so CreatedAt can be part of the (user_id,
+// created_at) index that expense reports and searches filter on.
 type ExpensesLog struct {
-	gorm.Model
-	Amount   float64 `json:"amount"`
-	Category string  `json:"category"`
-	UserID   string  `json:"user_id"`
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index:idx_expenses_logs_user_id_created_at,priority:2"`
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Amount    float64        `json:"amount"`
+	Category  string         `json:"category"`
+	UserID    string         `json:"user_id" gorm:"index:idx_expenses_logs_user_id_created_at,priority:1;size:191"`
+	// Set when the expense was logged by marking a reminder occurrence as paid
+	ReminderID           *uint `json:"reminder_id"`
+	ReminderOccurrenceID *uint `json:"reminder_occurrence_id"`
+	// Set when the expense is shared to the user's household ledger; unset keeps it personal
+	HouseholdID *uint `json:"household_id" gorm:"index"`
+	// Set when the expense was logged automatically from a recurring expense
+	RecurringExpenseID *uint `json:"recurring_expense_id"`
 }
 
+// ReminderStatus is where a reminder, or one of its occurrences, is in its lifecycle. Changes
+// between statuses go through the api package, which enforces the allowed transitions.
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "pending"
+	ReminderOverdue   ReminderStatus = "overdue"
+	ReminderPaid      ReminderStatus = "paid"
+	ReminderCancelled ReminderStatus = "cancelled"
+	ReminderSkipped   ReminderStatus = "skipped"
+)
+
 type RemindersLog struct {
 	gorm.Model
-	Amount        float64   `json:"amount"`
-	Recipient     string    `json:"recipient"`
-	GcashNumber   string    `json:"gcash_number"`
-	DueDate       time.Time `json:"due_date"`
-	Status        string    `json:"status"`
-	PaymentMethod string    `json:"payment_method"`
-	UserID        string    `json:"user_id"`
-	Notified      bool      `json:"notified"` // New field
-	ReminderType  string    `json:"reminder_type"`
-	Frequency     string    `json:"frequency"`
+	Amount          float64        `json:"amount"`
+	Recipient       string         `json:"recipient"`
+	AccountNumber   string         `json:"account_number"` // Mobile, bank or card number, depending on PaymentMethod
+	BankName        string         `json:"bank_name"`
+	TransferRail    string         `json:"transfer_rail"` // InstaPay or PESONet for bank transfers
+	DueDate         time.Time      `json:"due_date"`
+	Status          ReminderStatus `json:"status"`
+	StatusChangedAt *time.Time     `json:"status_changed_at"`
+	PaymentMethod   string         `json:"payment_method"`
+	UserID          string         `json:"user_id"`
+	Notified        bool           `json:"notified"` // New field
+	ReminderType    string         `json:"reminder_type"`
+	Frequency       string         `json:"frequency"`
+	LeadDays        string         `json:"lead_days"`        // Comma-separated days of advance notice; empty uses the user's default
+	DueTimeSet      bool           `json:"due_time_set"`     // DueDate carries a time of day; otherwise the user's default time applies
+	SnoozedUntil    *time.Time     `json:"snoozed_until"`    // Notifications are held back until this time
+	SnoozeCount     int            `json:"snooze_count"`     // Snoozes used for the current due date
+	EscalationCount int            `json:"escalation_count"` // Overdue nudges sent so far
+	LastEscalatedAt *time.Time     `json:"last_escalated_at"`
+	RRule           string         `json:"rrule"`            // RFC 5545 recurrence rule; empty falls back to Frequency
+	RecurrenceStart *time.Time     `json:"recurrence_start"` // First due date of the series (the rule's DTSTART)
+	Category        string         `json:"category"`         // Expense category used when a payment is auto-logged
+	PayeeID         *uint          `json:"payee_id" gorm:"index"`
+}
+
+// ReminderSnooze records each time a reminder notification was snoozed.
+type ReminderSnooze struct {
+	gorm.Model
+	ReminderID   uint      `json:"reminder_id" gorm:"index"`
+	DueDate      time.Time `json:"due_date"`
+	Option       string    `json:"option"`
+	SnoozedUntil time.Time `json:"snoozed_until"`
+}
+
+// ReminderNotification records an advance notice already sent for one due date of a reminder,
+// so each lead-time stage is delivered at most once.
+type ReminderNotification struct {
+	gorm.Model
+	ReminderID uint      `json:"reminder_id" gorm:"uniqueIndex:idx_reminder_stage"`
+	DueDate    time.Time `json:"due_date" gorm:"uniqueIndex:idx_reminder_stage"`
+	LeadDays   int       `json:"lead_days" gorm:"uniqueIndex:idx_reminder_stage"`
+}
+
+type UserPreference struct {
+	gorm.Model
+	UserID             string     `json:"user_id" gorm:"uniqueIndex:idx_user_id;size:255;not null"`
+	ReportFrequency    string     `json:"report_frequency" gorm:"size:50;not null;default:none"`
+	LastReportSent     *time.Time `json:"last_report_sent"`
+	AnomalySensitivity string     `json:"anomaly_sensitivity" gorm:"size:20;not null;default:medium"`
+	ReminderLeadDays   string     `json:"reminder_lead_days" gorm:"size:50;not null;default:3,1"`
+	ReminderTime       string     `json:"reminder_time" gorm:"size:5;not null;default:09:00"`
+	Timezone           string     `json:"timezone" gorm:"size:64;not null;default:Asia/Manila"`
+}
+
+type SavingsGoal struct {
+	gorm.Model
+	UserID       string     `json:"user_id" gorm:"index"`
+	Name         string     `json:"name"`
+	TargetAmount float64    `json:"target_amount"`
+	SavedAmount  float64    `json:"saved_amount"`
+	TargetDate   *time.Time `json:"target_date"`
+}
+
+type GoalContribution struct {
+	gorm.Model
+	GoalID uint    `json:"goal_id" gorm:"index"`
+	UserID string  `json:"user_id"`
+	Amount float64 `json:"amount"`
+}
+
+// ReminderOccurrence is a single instance of a reminder's schedule, with its own due date,
+// notification and payment status. One-time reminders have at most one occurrence.
+type ReminderOccurrence struct {
+	gorm.Model
+	ReminderID uint           `json:"reminder_id" gorm:"uniqueIndex:idx_reminder_occurrence"`
+	UserID     string         `json:"user_id" gorm:"index"`
+	DueDate    time.Time      `json:"due_date" gorm:"uniqueIndex:idx_reminder_occurrence"`
+	Notified   bool           `json:"notified"`
+	NotifiedAt *time.Time     `json:"notified_at"`
+	Status     ReminderStatus `json:"status"` // pending, paid or skipped
+	PaidAt     *time.Time     `json:"paid_at"`
+	ExpenseID  *uint          `json:"expense_id"` // Expense logged for this payment
+}
+
+// ReminderStatusChange records each status transition of a reminder.
+type ReminderStatusChange struct {
+	gorm.Model
+	ReminderID uint           `json:"reminder_id" gorm:"index"`
+	FromStatus ReminderStatus `json:"from_status"`
+	ToStatus   ReminderStatus `json:"to_status"`
+	ChangedAt  time.Time      `json:"changed_at"`
+}
+
+// Payee is a saved recipient with the payment details used to pay them.
+type Payee struct {
+	gorm.Model
+	UserID        string   `json:"user_id" gorm:"uniqueIndex:idx_payee_user_name;size:191"`
+	Name          string   `json:"name" gorm:"uniqueIndex:idx_payee_user_name;size:191"`
+	PaymentMethod string   `json:"payment_method"`
+	AccountNumber string   `json:"account_number"`
+	BankName      string   `json:"bank_name"`
+	TransferRail  string   `json:"transfer_rail"`
+	DefaultAmount *float64 `json:"default_amount"` // Used when a reminder for the payee doesn't give an amount
+}
+
+// PaymentProof is an uploaded image, such as a transfer receipt screenshot, kept as proof of a
+// reminder payment. The image lives in the blob store; ReminderID is set once the user picks
+// the reminder it belongs to.
+type PaymentProof struct {
+	gorm.Model
+	UserID       string `json:"user_id" gorm:"index;size:191"`
+	ReminderID   *uint  `json:"reminder_id" gorm:"index"`
+	OccurrenceID *uint  `json:"occurrence_id"` // The payment the proof was attached to
+	BlobKey      string `json:"blob_key"`
+	ContentType  string `json:"content_type"`
+	Size         int    `json:"size"`
+}
+
+// ExpenseReceipt is a receipt photo the user attached to an expense. The image itself is kept
+// in the blob store under BlobKey.
+type ExpenseReceipt struct {
+	gorm.Model
+	UserID      string `json:"user_id" gorm:"index;size:191"`
+	ExpenseID   uint   `json:"expense_id" gorm:"index"`
+	BlobKey     string `json:"blob_key"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// SplitExpense is an expense the user paid for and shared with others. The user's own share is
+// logged as ExpenseID; what everyone else owes is kept as SplitShares.
+type SplitExpense struct {
+	gorm.Model
+	UserID      string  `json:"user_id" gorm:"index;size:191"`
+	ExpenseID   *uint   `json:"expense_id" gorm:"index"` // Unset when others cover the whole amount
+	Category    string  `json:"category"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+// SplitShare is what one person owes the user for a split expense.
+type SplitShare struct {
+	gorm.Model
+	SplitID uint    `json:"split_id" gorm:"index"`
+	UserID  string  `json:"user_id" gorm:"index;size:191"`
+	Person  string  `json:"person"`
+	Amount  float64 `json:"amount"`
+}
+
+// SplitSettlement records someone paying the user back for split expenses. Settle-ups are now
+// recorded as DebtEntry repayments; these older rows still count towards the balance.
+type SplitSettlement struct {
+	gorm.Model
+	UserID    string    `json:"user_id" gorm:"index;size:191"`
+	Person    string    `json:"person"`
+	Amount    float64   `json:"amount"`
+	SettledAt time.Time `json:"settled_at"`
+}
+
+// Household is a shared ledger that several users log expenses to, joined with its invite code.
+type Household struct {
+	gorm.Model
+	Name       string `json:"name"`
+	InviteCode string `json:"invite_code" gorm:"uniqueIndex;size:16"`
+	OwnerID    string `json:"owner_id"`
+}
+
+// HouseholdMember links a user to the household they belong to. A user is in at most one.
+type HouseholdMember struct {
+	gorm.Model
+	HouseholdID uint   `json:"household_id" gorm:"index"`
+	UserID      string `json:"user_id" gorm:"uniqueIndex;size:191"`
+	DisplayName string `json:"display_name"` // Shown in household reports
+}
+
+// DebtKind is what a debt entry records between the user and another person.
+type DebtKind string
+
+const (
+	DebtLent       DebtKind = "lent"         // The user lent money; the person owes the user
+	DebtBorrowed   DebtKind = "borrowed"     // The user borrowed money; the user owes the person
+	DebtRepaidToMe DebtKind = "repaid_to_me" // The person paid the user back
+	DebtRepaidByMe DebtKind = "repaid_by_me" // The user paid the person back
+)
+
+// DebtEntry is one loan or repayment in the running balance between the user and a person.
+type DebtEntry struct {
+	gorm.Model
+	UserID     string     `json:"user_id" gorm:"index;size:191"`
+	Person     string     `json:"person"`
+	Kind       DebtKind   `json:"kind"`
+	Amount     float64    `json:"amount"`
+	DueDate    *time.Time `json:"due_date"`    // When a loan should be repaid
+	ReminderID *uint      `json:"reminder_id"` // Reminder sent on the due date
+}
+
+// RecurringExpense is a fixed cost, such as rent or a subscription, that is logged as an
+// expense automatically on its schedule.
+type RecurringExpense struct {
+	gorm.Model
+	UserID       string     `json:"user_id" gorm:"index;size:191"`
+	Amount       float64    `json:"amount"`
+	Category     string     `json:"category"`
+	RRule        string     `json:"rrule"`                    // RFC 5545 recurrence rule
+	StartDate    time.Time  `json:"start_date"`               // First date of the series (the rule's DTSTART)
+	NextRunAt    *time.Time `json:"next_run_at" gorm:"index"` // Next date to log; nil once the schedule has ended
+	LastLoggedAt *time.Time `json:"last_logged_at"`
+}
+
+// SubscriptionStatus is where a user's premium subscription is in its lifecycle.
+type SubscriptionStatus string
+
+const (
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionExpired  SubscriptionStatus = "expired"
+)
+
+// Subscription is a user's premium plan. There is one per user; renewing or switching plans
+// updates it, and a lapsed subscription stays around as expired so the trial can't be reused.
+type Subscription struct {
+	gorm.Model
+	UserID                string             `json:"user_id" gorm:"uniqueIndex;size:191"`
+	PlanKey               string             `json:"plan_key"`
+	Status                SubscriptionStatus `json:"status" gorm:"size:20"`
+	CurrentPeriodEnd      time.Time          `json:"current_period_end" gorm:"index"` // End of the trial or of the paid period
+	CancelAtPeriodEnd     bool               `json:"cancel_at_period_end"`            // No renewal reminder; lapses at the period end
+	RenewalReminderSentAt *time.Time         `json:"renewal_reminder_sent_at"`        // Cleared whenever the period is extended
+}
+
+// SubscriptionPayment is a charge taken for a subscription period.
+type SubscriptionPayment struct {
+	gorm.Model
+	SubscriptionID    uint      `json:"subscription_id" gorm:"index"`
+	UserID            string    `json:"user_id" gorm:"index;size:191"`
+	PlanKey           string    `json:"plan_key"`
+	Amount            float64   `json:"amount"`
+	ProviderReference string    `json:"provider_reference"`
+	PeriodEnd         time.Time `json:"period_end"`
 }
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"time"
+)
+
+// handleLoan records money lent or borrowed, e.g. "lent 500 to ana due 06/30".
+func handleLoan(message, psid, token string) {
+	loan, err := utils.GetLoanDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing loan for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Please use the format: lent [amount] to [name] or borrowed [amount] from [name], optionally followed by due [month/day]", err), psid, token)
+		return
+	}
+
+	entry, err := api.RecordLoan(psid, loan)
+	if err != nil {
+		fmt.Printf("Error recording loan for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't record that. Please try again later.", psid, token)
+		return
+	}
+
+	reply := fmt.Sprintf("Noted! You borrowed ₱%.2f from %s.", entry.Amount, entry.Person)
+	if loan.Lent {
+		reply = fmt.Sprintf("Noted! You lent ₱%.2f to %s.", entry.Amount, entry.Person)
+	}
+	if loan.DueDate != nil {
+		reply += fmt.Sprintf(" I'll remind you on %s.", utils.FormatDueDate(*loan.DueDate, loan.HasTime))
+	}
+	if debt, err := api.GetDebtBalance(psid, entry.Person); err == nil {
+		reply += "\n" + describeDebtBalance(*debt)
+	} else {
+		fmt.Printf("Error fetching debt balance with %s for user %s: %v\n", entry.Person, psid, err)
+	}
+	utils.SendTextMessage(reply, psid, token)
+}
+
+// handleRepayment records a repayment, e.g. "ana paid back 200" or "paid back ben 100".
+func handleRepayment(message, psid, token string) {
+	person, amount, toMe, err := utils.GetRepaymentDataFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage("Please use the format: [name] paid back [amount], or paid back [name] [amount] for money you returned.", psid, token)
+		return
+	}
+
+	debt, err := api.RecordRepayment(psid, person, amount, toMe, time.Now())
+	if errors.Is(err, api.ErrNoDebt) {
+		if toMe {
+			utils.SendTextMessage(fmt.Sprintf("%s doesn't owe you anything right now.", person), psid, token)
+		} else {
+			utils.SendTextMessage(fmt.Sprintf("You don't owe %s anything right now.", person), psid, token)
+		}
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error recording repayment with %s for user %s: %v\n", person, psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't record that: %v.", err), psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Recorded ₱%.2f paid back. %s", amount, describeDebtBalance(*debt)), psid, token)
+}
+
+// describeDebtBalance states the running balance with a person in a sentence.
+func describeDebtBalance(debt utils.PersonDebt) string {
+	switch {
+	case debt.Balance >= 0.005:
+		return fmt.Sprintf("%s owes you ₱%.2f in total.", debt.Name, debt.Balance)
+	case debt.Balance <= -0.005:
+		return fmt.Sprintf("You owe %s ₱%.2f in total.", debt.Name, -debt.Balance)
+	default:
+		return fmt.Sprintf("You and %s are all settled!", debt.Name)
+	}
+}
+
+func sendDebtSummary(psid, token string) {
+	debts, err := api.GetDebtBalances(psid)
+	if err != nil {
+		fmt.Printf("Error fetching debts for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your debts at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	for _, chunk := range utils.SplitMessage(utils.GetDebtSummaryReport(debts), utils.MessengerTextLimit) {
+		utils.SendTextMessage(chunk, psid, token)
+	}
+}
+
+func sendDebtHistory(person string, psid, token string) {
+	entries, err := api.GetDebtHistory(psid, person)
+	if err == nil && len(entries) > 0 {
+		person = entries[0].Person
+	}
+	var debt *utils.PersonDebt
+	if err == nil {
+		debt, err = api.GetDebtBalance(psid, person)
+	}
+	if err != nil {
+		fmt.Printf("Error fetching debt history with %s for user %s: %v\n", person, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch that history at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	for _, chunk := range utils.SplitMessage(utils.GetDebtHistoryMessage(person, entries, debt.Balance), utils.MessengerTextLimit) {
+		utils.SendTextMessage(chunk, psid, token)
+	}
+}
+
+// handleViewDebt shows the debt history behind a debt reminder card.
+func handleViewDebt(reminderID string, psid, token string) {
+	reminder, err := api.GetReminderByID(reminderID)
+	if err != nil || reminder.UserID != psid {
+		fmt.Printf("Error fetching debt reminder %s for user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't find that debt.", psid, token)
+		return
+	}
+	sendDebtHistory(reminder.Recipient, psid, token)
+}
+
+// sendDebtReminder tells the user a loan is due, with what is still outstanding. Nothing is sent
+// once the balance has been settled.
+func sendDebtReminder(reminder models.RemindersLog, token string) error {
+	entry, debt, err := api.GetDebtForReminder(reminder)
+	if err != nil {
+		return fmt.Errorf("error fetching debt: %w", err)
+	}
+
+	var message string
+	switch {
+	case entry.Kind == models.DebtLent && debt.Balance >= 0.005:
+		message = fmt.Sprintf("Heads up! The ₱%.2f you lent to %s is due today (%s). %s still owes you ₱%.2f.",
+			entry.Amount, debt.Name, formatDueForMessage(reminder), debt.Name, debt.Balance)
+	case entry.Kind == models.DebtBorrowed && debt.Balance <= -0.005:
+		message = fmt.Sprintf("Heads up! The ₱%.2f you borrowed from %s is due today (%s). You still owe ₱%.2f.",
+			entry.Amount, debt.Name, formatDueForMessage(reminder), -debt.Balance)
+	default:
+		fmt.Printf("Reminder Processor: Debt for reminder ID %d is already settled. Skipping.\n", reminder.ID)
+		return nil
+	}
+
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "View debt", Payload: fmt.Sprintf("VIEW_DEBT_%d", reminder.ID)},
+	}
+	return utils.SendQuickReplies(message, quickReplies, reminder.UserID, token)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+)
+
+// WarnIfUnusualExpense compares a freshly saved expense against the user's baseline for
+// its category and, if it is far outside it, asks the user to confirm or undo the entry.
+func WarnIfUnusualExpense(expense *models.ExpensesLog, baseline utils.CategoryBaseline, psid, token string) {
+	sensitivity := "medium"
+	preference, err := api.GetUserPreference(psid)
+	if err != nil {
+		fmt.Printf("Error fetching preferences for user %s: %v\n", psid, err)
+	} else {
+		sensitivity = preference.AnomalySensitivity
+	}
+
+	if !utils.IsUnusualAmount(expense.Amount, baseline, sensitivity) {
+		return
+	}
+
+	message := fmt.Sprintf("Heads up! ₱%.2f for %s is unusual for you. You usually spend around ₱%.2f on %s. Is this correct?",
+		expense.Amount, expense.Category, baseline.Median, expense.Category)
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Yes, keep it", Payload: fmt.Sprintf("KEEP_EXPENSE_%d", expense.ID)},
+		{ContentType: "text", Title: "No, remove it", Payload: fmt.Sprintf("UNDO_EXPENSE_%d", expense.ID)},
+	}
+
+	err = utils.SendQuickReplies(message, quickReplies, psid, token)
+	if err != nil {
+		fmt.Printf("Error sending unusual expense warning for user %s: %v\n", psid, err)
+	} else {
+		fmt.Printf("Unusual expense flagged for user %s: ₱%.2f on %s (median ₱%.2f)\n", psid, expense.Amount, expense.Category, baseline.Median)
+	}
+}
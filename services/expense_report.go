@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"strings"
+)
+
+var reportLabels = map[string]string{
+	"day":   "Daily",
+	"week":  "Weekly",
+	"month": "Monthly",
+}
+
+// SendExpenseReport sends one page of the expense report for the given range ("day", "week"
+// or "month"). Text over the Messenger limit is split across messages, and if more
+// categories remain a "Show more" quick reply is attached to page through them.
+func SendExpenseReport(rangeType string, page int, psid, token string) {
+	label, ok := reportLabels[rangeType]
+	if !ok {
+		fmt.Printf("Unknown report range %s for user %s\n", rangeType, psid)
+		utils.SendTextMessage("Sorry, I don't know that report range.", psid, token)
+		return
+	}
+
+	expenses, err := api.GetExpensesByUserAndRange(psid, rangeType)
+	if err != nil {
+		fmt.Printf("Error fetching %s expenses for user %s: %v\n", strings.ToLower(label), psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your expense report at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	report, hasMore := utils.GetExpenseReportPage(expenses, label, page)
+	chunks := utils.SplitMessage(report, utils.MessengerTextLimit)
+
+	var quickReplies []templates.QuickReply
+	if hasMore {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Show more", Payload: fmt.Sprintf("REPORT_MORE_%s_%d", strings.ToUpper(rangeType), page+1),
+		})
+	}
+	if page == 0 && len(expenses) > 0 {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "By category", Payload: fmt.Sprintf("REPORT_CARDS_%s", strings.ToUpper(rangeType)),
+		})
+	}
+
+	for i, chunk := range chunks {
+		if len(quickReplies) > 0 && i == len(chunks)-1 {
+			err = utils.SendQuickReplies(chunk, quickReplies, psid, token)
+		} else {
+			err = utils.SendTextMessage(chunk, psid, token)
+		}
+		if err != nil {
+			fmt.Printf("Error sending %s report to user %s: %v\n", strings.ToLower(label), psid, err)
+			return
+		}
+	}
+}
+
+// maxCarouselElements is the most elements Messenger accepts in one generic template.
+const maxCarouselElements = 10
+
+// SendCategoryCards sends the expense report for a range as carousels of category cards.
+func SendCategoryCards(rangeType string, psid, token string) {
+	label, ok := reportLabels[rangeType]
+	if !ok {
+		fmt.Printf("Unknown report range %s for user %s\n", rangeType, psid)
+		utils.SendTextMessage("Sorry, I don't know that report range.", psid, token)
+		return
+	}
+
+	expenses, err := api.GetExpensesByUserAndRange(psid, rangeType)
+	if err != nil {
+		fmt.Printf("Error fetching %s expenses for user %s: %v\n", strings.ToLower(label), psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your expense report at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	cards := utils.GetCategoryCards(expenses, rangeType)
+	if len(cards) == 0 {
+		utils.SendTextMessage(fmt.Sprintf("You have no expenses in your %s report.", strings.ToLower(label)), psid, token)
+		return
+	}
+
+	for start := 0; start < len(cards); start += maxCarouselElements {
+		end := start + maxCarouselElements
+		if end > len(cards) {
+			end = len(cards)
+		}
+		if err := utils.SendTemplateMessage(cards[start:end], psid, token); err != nil {
+			fmt.Printf("Error sending category cards to user %s: %v\n", psid, err)
+			return
+		}
+	}
+}
+
+// SendCategoryItems lists one page of the expenses behind a category card.
+func SendCategoryItems(category string, rangeType string, page int, psid, token string) {
+	label, ok := reportLabels[rangeType]
+	if !ok {
+		fmt.Printf("Unknown report range %s for user %s\n", rangeType, psid)
+		utils.SendTextMessage("Sorry, I don't know that report range.", psid, token)
+		return
+	}
+
+	expenses, count, err := api.GetExpensesByCategoryAndRange(psid, category, rangeType, page*utils.CategoryItemsPageSize, utils.CategoryItemsPageSize)
+	if err != nil {
+		fmt.Printf("Error fetching %s expenses for user %s: %v\n", category, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch those expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	// Payloads come back upper-cased, so show the category as it was logged.
+	if len(expenses) > 0 {
+		category = expenses[0].Category
+	}
+	message := utils.GetCategoryItemsMessage(category, label, expenses, page, count)
+
+	var quickReplies []templates.QuickReply
+	if page > 0 {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Previous", Payload: fmt.Sprintf("VIEW_CATEGORY_%s_%s_%d", category, strings.ToUpper(rangeType), page-1),
+		})
+	}
+	if int64((page+1)*utils.CategoryItemsPageSize) < count {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Next", Payload: fmt.Sprintf("VIEW_CATEGORY_%s_%s_%d", category, strings.ToUpper(rangeType), page+1),
+		})
+	}
+
+	if len(quickReplies) > 0 {
+		err = utils.SendQuickReplies(message, quickReplies, psid, token)
+	} else {
+		err = utils.SendTextMessage(message, psid, token)
+	}
+	if err != nil {
+		fmt.Printf("Error sending category items to user %s: %v\n", psid, err)
+	}
+}
+
+// parseCategoryPayload splits the body of a VIEW_CATEGORY_<name>_<RANGE>[_<page>] payload.
+// It parses from the right so category names may themselves contain underscores.
+func parseCategoryPayload(body string) (category string, rangeType string, page int, ok bool) {
+	parts := strings.Split(body, "_")
+	if len(parts) >= 3 {
+		if p, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			if _, known := reportLabels[strings.ToLower(parts[len(parts)-2])]; known {
+				return strings.Join(parts[:len(parts)-2], "_"), strings.ToLower(parts[len(parts)-2]), p, true
+			}
+		}
+	}
+	if len(parts) >= 2 {
+		if _, known := reportLabels[strings.ToLower(parts[len(parts)-1])]; known {
+			return strings.Join(parts[:len(parts)-1], "_"), strings.ToLower(parts[len(parts)-1]), 0, true
+		}
+	}
+	return "", "", 0, false
+}
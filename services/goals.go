@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/utils"
+	"time"
+)
+
+func handleNewGoal(message, psid, token string) {
+	amount, name, targetDate, err := utils.GetGoalDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing goal data for user %s: %v\n", psid, err)
+		utils.SendTextMessage("There was an issue parsing your goal. Please use the format: goal [amount] for [name] by [month/year]", psid, token)
+		return
+	}
+
+	goal, err := api.SaveGoal(psid, name, amount, targetDate)
+	if err != nil {
+		fmt.Printf("Error saving goal for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't create that goal: %v", err), psid, token)
+		return
+	}
+
+	reply := fmt.Sprintf("Goal created! Save ₱%.2f for %s", goal.TargetAmount, goal.Name)
+	if goal.TargetDate != nil {
+		reply += fmt.Sprintf(" by %s. That's about ₱%.2f a month.", goal.TargetDate.Format("Jan 2006"), utils.MonthlyAmountNeeded(*goal, time.Now()))
+	} else {
+		reply += "."
+	}
+	utils.SendTextMessage(reply, psid, token)
+	fmt.Printf("Goal saved for user %s: ₱%.2f for %s\n", psid, goal.TargetAmount, goal.Name)
+}
+
+func handleGoalContribution(message, psid, token string) {
+	amount, goalName, err := utils.GetGoalContributionDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing goal contribution for user %s: %v\n", psid, err)
+		utils.SendTextMessage("There was an issue parsing your savings. Please use the format: save [amount] to [goal name]", psid, token)
+		return
+	}
+
+	goal, err := api.AddGoalContribution(psid, goalName, amount)
+	if errors.Is(err, api.ErrGoalNotFound) {
+		utils.SendTextMessage(fmt.Sprintf("I couldn't find a goal named %s. Create it first with: goal [amount] for %s by [month/year]", goalName, goalName), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error saving goal contribution for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't record your savings. Please try again later.", psid, token)
+		return
+	}
+
+	fraction := 0.0
+	if goal.TargetAmount > 0 {
+		fraction = goal.SavedAmount / goal.TargetAmount
+	}
+	reply := fmt.Sprintf("Saved ₱%.2f to %s.\n%s %.0f%% (₱%.2f of ₱%.2f)", amount, goal.Name, utils.ProgressBar(fraction, 10), fraction*100, goal.SavedAmount, goal.TargetAmount)
+	if goal.SavedAmount >= goal.TargetAmount {
+		reply += "\nYou've reached your goal! 🎉"
+	}
+	utils.SendTextMessage(reply, psid, token)
+}
+
+func sendGoalsView(psid, token string) {
+	goals, err := api.GetGoals(psid)
+	if err != nil {
+		fmt.Printf("Error fetching goals for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your savings goals at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	report := utils.GetGoalsReport(goals, time.Now())
+	for _, chunk := range utils.SplitMessage(report, utils.MessengerTextLimit) {
+		if err := utils.SendTextMessage(chunk, psid, token); err != nil {
+			fmt.Printf("Error sending goals view to user %s: %v\n", psid, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"sync"
+)
+
+// lastSearch keeps each user's most recent search so result pages can be requested by postback.
+// Webhook events are handled concurrently, so access goes through lastSearchMu.
+var (
+	lastSearch   = make(map[string]api.ExpenseSearch)
+	lastSearchMu sync.Mutex
+)
+
+func sendExpenseHistory(page int, psid, token string) {
+	offset := page * utils.ExpenseListPageSize
+	expenses, count, err := api.GetRecentExpenses(psid, offset, utils.ExpenseListPageSize)
+	if err != nil {
+		fmt.Printf("Error fetching expense history for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your expense history at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	message := utils.GetExpenseListMessage("Your expense history", expenses, offset, count)
+	sendExpensePage(message, "HISTORY_PAGE_", page, count, psid, token)
+}
+
+func startExpenseSearch(message, psid, token string) {
+	text, minAmount, maxAmount, err := utils.GetSearchDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing search for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Please search in this format: search [text] [amount range]\n(e.g. search coffee, search 100-500, search food >1000)", psid, token)
+		return
+	}
+
+	lastSearchMu.Lock()
+	lastSearch[psid] = api.ExpenseSearch{Text: text, MinAmount: minAmount, MaxAmount: maxAmount}
+	lastSearchMu.Unlock()
+	sendSearchResults(0, psid, token)
+}
+
+func sendSearchResults(page int, psid, token string) {
+	lastSearchMu.Lock()
+	search, exists := lastSearch[psid]
+	lastSearchMu.Unlock()
+	if !exists {
+		utils.SendTextMessage("Your search has expired. Please search again.", psid, token)
+		return
+	}
+
+	offset := page * utils.ExpenseListPageSize
+	expenses, count, err := api.SearchExpenses(psid, search, offset, utils.ExpenseListPageSize)
+	if err != nil {
+		fmt.Printf("Error searching expenses for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't search your expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	message := utils.GetExpenseListMessage("Search results", expenses, offset, count)
+	sendExpensePage(message, "SEARCH_PAGE_", page, count, psid, token)
+}
+
+// sendExpensePage sends a page of an expense list with Previous/Next quick replies as needed.
+func sendExpensePage(message, payloadPrefix string, page int, count int64, psid, token string) {
+	var quickReplies []templates.QuickReply
+	if page > 0 {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Previous", Payload: fmt.Sprintf("%s%d", payloadPrefix, page-1),
+		})
+	}
+	if int64((page+1)*utils.ExpenseListPageSize) < count {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Next", Payload: fmt.Sprintf("%s%d", payloadPrefix, page+1),
+		})
+	}
+
+	var err error
+	if len(quickReplies) > 0 {
+		err = utils.SendQuickReplies(message, quickReplies, psid, token)
+	} else {
+		err = utils.SendTextMessage(message, psid, token)
+	}
+	if err != nil {
+		fmt.Printf("Error sending expense list to user %s: %v\n", psid, err)
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strings"
+)
+
+// memberDisplayName is the name a user is shown under in household reports, taken from their
+// Messenger profile when it can be read.
+func memberDisplayName(psid, token string) string {
+	name, err := utils.GetUserFirstName(psid, token)
+	if err != nil || name == "" {
+		if err != nil {
+			fmt.Printf("Error fetching profile name for user %s: %v\n", psid, err)
+		}
+		return "Member"
+	}
+	return name
+}
+
+func handleCreateHousehold(message, psid, token string) {
+	name, err := utils.GetHouseholdNameFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage("Please use the format: create household [name]\n(e.g. create household Casa Reyes)", psid, token)
+		return
+	}
+
+	household, err := api.CreateHousehold(psid, name, memberDisplayName(psid, token))
+	if errors.Is(err, api.ErrAlreadyInHousehold) {
+		utils.SendTextMessage("You're already in a household. Type \"leave household\" first to start a new one.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error creating household for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't create your household. Please try again later.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Household %s created! Share this invite code so others can join:\n\njoin household %s\n\nAfter logging an expense, tap \"Add to household\" to share it.", household.Name, household.InviteCode), psid, token)
+}
+
+func handleJoinHousehold(message, psid, token string) {
+	code, err := utils.GetInviteCodeFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage("Please use the format: join household [invite code]", psid, token)
+		return
+	}
+
+	household, err := api.JoinHousehold(psid, code, memberDisplayName(psid, token))
+	if errors.Is(err, api.ErrAlreadyInHousehold) {
+		utils.SendTextMessage("You're already in a household. Type \"leave household\" first to join another one.", psid, token)
+		return
+	}
+	if errors.Is(err, api.ErrInvalidInviteCode) {
+		utils.SendTextMessage(fmt.Sprintf("I couldn't find a household with the code %s. Please check it and try again.", code), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error joining household for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't add you to that household. Please try again later.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Welcome to %s! After logging an expense, tap \"Add to household\" to share it. Type \"household report\" to see everyone's shared spending.", household.Name), psid, token)
+}
+
+func handleLeaveHousehold(psid, token string) {
+	household, err := api.LeaveHousehold(psid)
+	if errors.Is(err, api.ErrNoHousehold) {
+		utils.SendTextMessage("You're not in a household.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error leaving household for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't remove you from your household. Please try again later.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("You've left %s. Expenses you shared stay in its ledger.", household.Name), psid, token)
+}
+
+func sendHouseholdInfo(psid, token string) {
+	household, err := api.GetUserHousehold(psid)
+	if errors.Is(err, api.ErrNoHousehold) {
+		utils.SendTextMessage("You're not in a household yet. Start one with \"create household [name]\", or join one with \"join household [invite code]\".", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error fetching household for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your household at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	members, err := api.GetHouseholdMembers(household.ID)
+	if err != nil {
+		fmt.Printf("Error fetching members of household %d: %v\n", household.ID, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your household at the moment. Please try again later.", psid, token)
+		return
+	}
+	utils.SendTextMessage(utils.GetHouseholdInfoMessage(*household, members), psid, token)
+}
+
+// sendHouseholdReport sends the shared spending of the user's household for a range ("day",
+// "week" or "month"), by member and by category.
+func sendHouseholdReport(rangeType string, psid, token string) {
+	label, ok := reportLabels[rangeType]
+	if !ok {
+		fmt.Printf("Unknown report range %s for user %s\n", rangeType, psid)
+		utils.SendTextMessage("Sorry, I don't know that report range.", psid, token)
+		return
+	}
+
+	household, err := api.GetUserHousehold(psid)
+	if errors.Is(err, api.ErrNoHousehold) {
+		utils.SendTextMessage("You're not in a household yet. Start one with \"create household [name]\".", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error fetching household for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your household report at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	expenses, err := api.GetExpensesByHouseholdAndRange(household.ID, rangeType)
+	if err != nil {
+		fmt.Printf("Error fetching %s expenses for household %d: %v\n", strings.ToLower(label), household.ID, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your household report at the moment. Please try again later.", psid, token)
+		return
+	}
+	members, err := api.GetHouseholdMembers(household.ID)
+	if err != nil {
+		fmt.Printf("Error fetching members of household %d: %v\n", household.ID, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your household report at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	memberNames := make(map[string]string)
+	for _, member := range members {
+		memberNames[member.UserID] = member.DisplayName
+	}
+	report := utils.GetHouseholdReport(*household, expenses, label, memberNames)
+	for _, chunk := range utils.SplitMessage(report, utils.MessengerTextLimit) {
+		utils.SendTextMessage(chunk, psid, token)
+	}
+}
+
+// sendExpenseSaved confirms a logged expense with an option to attach a receipt photo. Members
+// of a household are also offered to share it.
+func sendExpenseSaved(expense *models.ExpensesLog, message string, psid, token string) {
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Add receipt", Payload: fmt.Sprintf("ADD_RECEIPT_%d", expense.ID)},
+	}
+	if _, err := api.GetUserHousehold(psid); err == nil {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Add to household", Payload: fmt.Sprintf("SHARE_EXPENSE_%d", expense.ID),
+		})
+	} else if !errors.Is(err, api.ErrNoHousehold) {
+		fmt.Printf("Error fetching household for user %s: %v\n", psid, err)
+	}
+
+	if err := utils.SendQuickReplies(message, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending expense confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+func handleShareExpense(expenseID string, psid, token string) {
+	household, err := api.ShareExpenseWithHousehold(psid, expenseID)
+	if errors.Is(err, api.ErrNoHousehold) {
+		utils.SendTextMessage("You're not in a household anymore, so the expense stays personal.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error sharing expense %s for user %s: %v\n", expenseID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't share that expense.", psid, token)
+		return
+	}
+
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Keep it personal", Payload: "UNSHARE_EXPENSE_" + expenseID},
+	}
+	if err := utils.SendQuickReplies(fmt.Sprintf("Added to %s's shared ledger.", household.Name), quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending share confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+func handleUnshareExpense(expenseID string, psid, token string) {
+	if err := api.UnshareExpense(psid, expenseID); err != nil {
+		fmt.Printf("Error unsharing expense %s for user %s: %v\n", expenseID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't update that expense.", psid, token)
+		return
+	}
+	utils.SendTextMessage("Okay, that expense is personal again.", psid, token)
+}
@@ -3,21 +3,42 @@ package services
 import (
 	"fmt"
 	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
 	"quickyexpensetracker/templates"
 	"quickyexpensetracker/utils"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-var userState = make(map[string]string)
+// userState is where each user is in a multi-step flow, such as logging an expense. Webhook
+// events are handled concurrently, so access goes through getUserState and setUserState.
+var (
+	userState   = make(map[string]string)
+	userStateMu sync.Mutex
+)
 var currentTime = time.Now()
 
+func getUserState(psid string) (string, bool) {
+	userStateMu.Lock()
+	defer userStateMu.Unlock()
+	state, exists := userState[psid]
+	return state, exists
+}
+
+func setUserState(psid, state string) {
+	userStateMu.Lock()
+	defer userStateMu.Unlock()
+	userState[psid] = state
+}
+
 func ProcessMainCommand(command, psid, mid, token string) {
 	fmt.Printf("Processing Command: %s, PSID: %s, MID: %s\n", command, psid, mid)
 
 	switch command {
 	case "GET_STARTED":
-		utils.SendGenerateRequest(templates.MenuTemplate[1], psid, token)
+		SendMainMenu(psid, token)
 	case "LOG_EXPENSES_MENU":
 		utils.SendGenerateRequest(templates.MenuTemplate[2], psid, token)
 	case "LOG_EXPENSES":
@@ -31,9 +52,11 @@ func ProcessMainCommand(command, psid, mid, token string) {
 	case "GENERATE_REPORT_MONTH":
 		ProcessTextMessageSent("REPORT_LOG_MONTH", psid, mid, token)
 	case "REMIND_PAYMENTS_MENU":
-		utils.SendGenerateRequest(templates.MenuTemplate[3], psid, token)
+		utils.SendTemplateMessage([]templates.Template{templates.MenuTemplate[3], templates.SubMenuTemplate[3]}, psid, token)
 	case "VIEW_PENDING_PAYMENTS":
 		ProcessTextMessageSent("VIEW_PENDING_PAYMENTS_MESSAGE", psid, mid, token)
+	case "VIEW_OVERDUE_PAYMENTS":
+		ProcessTextMessageSent("VIEW_OVERDUE_PAYMENTS_MESSAGE", psid, mid, token)
 	case "VIEW_ACCOMPLISHED_PAYMENTS":
 		ProcessTextMessageSent("VIEW_ACCOMPLISHED_PAYMENTS_MESSAGE", psid, mid, token)
 	case "SUBSCRIPTION_STATUS":
@@ -46,25 +69,148 @@ func ProcessMainCommand(command, psid, mid, token string) {
 		ProcessTextMessageSent("RESET_LOGS_MESSAGE", psid, mid, token)
 	case "SET_REMINDER":
 		ProcessTextMessageSent("SET_REMINDER_MESSAGE", psid, mid, token)
+	case "EXPENSE_ALERTS_SETTINGS":
+		ProcessTextMessageSent("EXPENSE_ALERTS_SETTINGS_MESSAGE", psid, mid, token)
+	case "NEW_GOAL":
+		ProcessTextMessageSent("NEW_GOAL_MESSAGE", psid, mid, token)
+	case "ADD_GOAL_SAVINGS":
+		ProcessTextMessageSent("ADD_GOAL_SAVINGS_MESSAGE", psid, mid, token)
+	case "VIEW_GOALS":
+		sendGoalsView(psid, token)
+	case "WHO_OWES_ME":
+		sendOwedBalances(psid, token)
+	case "CANCEL_SUBSCRIPTION":
+		handleCancelSubscription(psid, token)
 	default:
-		if strings.HasPrefix(command, "PAY_GCASH_") {
-			reminderID := strings.TrimPrefix(command, "PAY_GCASH_")
-			deepLink, err := api.GetGcashDeepLink(reminderID)
-			if err != nil {
-				fmt.Printf("Error getting Gcash deep link for reminder %s, user %s: %v\n", reminderID, psid, err)
-				utils.SendTextMessage("Sorry, could not retrieve Gcash link.", psid, token)
-			} else {
-				message := fmt.Sprintf("Click here to open Gcash: %s", deepLink)
-				utils.SendTextMessage(message, psid, token)
-			}
+		if strings.HasPrefix(command, "PAY_REMINDER_") {
+			sendPaymentInstructions(strings.TrimPrefix(command, "PAY_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "PAY_GCASH_") {
+			// Cards sent before other payment methods were added
+			sendPaymentInstructions(strings.TrimPrefix(command, "PAY_GCASH_"), psid, token)
 		} else if strings.HasPrefix(command, "MARK_AS_PAID_") {
 			reminderID := strings.TrimPrefix(command, "MARK_AS_PAID_")
-			err := api.UpdateReminderStatus(reminderID, "paid")
+			occurrence, reminder, err := api.MarkReminderPaid(psid, reminderID, time.Now())
 			if err != nil {
 				fmt.Printf("Error updating reminder status for reminder %s, user %s: %v\n", reminderID, psid, err)
 				utils.SendTextMessage("Sorry, could not update payment status.", psid, token)
 			} else {
-				utils.SendTextMessage("Payment has been marked as paid.", psid, token)
+				confirmPayment(occurrence, reminder, psid, token)
+			}
+		} else if strings.HasPrefix(command, "PAID_CATEGORY_") {
+			handlePaymentCategory(strings.TrimPrefix(command, "PAID_CATEGORY_"), psid, token)
+		} else if strings.HasPrefix(command, "UNDO_PAYMENT_") {
+			handleUndoPayment(strings.TrimPrefix(command, "UNDO_PAYMENT_"), psid, token)
+		} else if strings.HasPrefix(command, "SUBSCRIBE_") {
+			handleSubscribe(strings.TrimPrefix(command, "SUBSCRIBE_"), psid, token)
+		} else if strings.HasPrefix(command, "START_TRIAL_") {
+			handleStartTrial(strings.TrimPrefix(command, "START_TRIAL_"), psid, token)
+		} else if strings.HasPrefix(command, "ATTACH_PROOF_") {
+			handleAttachProof(strings.TrimPrefix(command, "ATTACH_PROOF_"), psid, token)
+		} else if strings.HasPrefix(command, "DISCARD_PROOF_") {
+			handleDiscardProof(strings.TrimPrefix(command, "DISCARD_PROOF_"), psid, token)
+		} else if strings.HasPrefix(command, "VIEW_REMINDER_HISTORY_") {
+			reminderID := strings.TrimPrefix(command, "VIEW_REMINDER_HISTORY_")
+			reminder, occurrences, err := api.GetReminderOccurrences(psid, reminderID, 20)
+			if err != nil {
+				fmt.Printf("Error fetching history for reminder %s, user %s: %v\n", reminderID, psid, err)
+				utils.SendTextMessage("Sorry, I couldn't fetch the history for that reminder.", psid, token)
+			} else {
+				history := utils.GetOccurrenceHistoryMessage(*reminder, occurrences, time.Now())
+				for _, chunk := range utils.SplitMessage(history, utils.MessengerTextLimit) {
+					utils.SendTextMessage(chunk, psid, token)
+				}
+			}
+		} else if strings.HasPrefix(command, "REPORT_MORE_") {
+			// Payload format: REPORT_MORE_<RANGE>_<page>
+			parts := strings.Split(strings.TrimPrefix(command, "REPORT_MORE_"), "_")
+			if len(parts) != 2 {
+				fmt.Printf("Malformed report paging payload: %s\n", command)
+				return
+			}
+			page, err := strconv.Atoi(parts[1])
+			if err != nil {
+				fmt.Printf("Malformed report page in payload %s: %v\n", command, err)
+				return
+			}
+			SendExpenseReport(strings.ToLower(parts[0]), page, psid, token)
+		} else if strings.HasPrefix(command, "REPORT_CARDS_") {
+			SendCategoryCards(strings.ToLower(strings.TrimPrefix(command, "REPORT_CARDS_")), psid, token)
+		} else if strings.HasPrefix(command, "VIEW_CATEGORY_") {
+			category, rangeType, page, ok := parseCategoryPayload(strings.TrimPrefix(command, "VIEW_CATEGORY_"))
+			if !ok {
+				fmt.Printf("Malformed category payload: %s\n", command)
+				return
+			}
+			SendCategoryItems(category, rangeType, page, psid, token)
+		} else if strings.HasPrefix(command, "HISTORY_PAGE_") {
+			page, err := strconv.Atoi(strings.TrimPrefix(command, "HISTORY_PAGE_"))
+			if err != nil {
+				fmt.Printf("Malformed history page in payload %s: %v\n", command, err)
+				return
+			}
+			sendExpenseHistory(page, psid, token)
+		} else if strings.HasPrefix(command, "SEARCH_PAGE_") {
+			page, err := strconv.Atoi(strings.TrimPrefix(command, "SEARCH_PAGE_"))
+			if err != nil {
+				fmt.Printf("Malformed search page in payload %s: %v\n", command, err)
+				return
+			}
+			sendSearchResults(page, psid, token)
+		} else if strings.HasPrefix(command, "SNOOZE_") {
+			handleSnoozeCommand(strings.TrimPrefix(command, "SNOOZE_"), psid, token)
+		} else if strings.HasPrefix(command, "MANAGE_REMINDER_") {
+			handleManageReminder(strings.TrimPrefix(command, "MANAGE_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "EDIT_REMINDER_") {
+			handleEditReminder(strings.TrimPrefix(command, "EDIT_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "REMINDER_FIELD_") {
+			promptReminderEdit(strings.TrimPrefix(command, "REMINDER_FIELD_"), psid, token)
+		} else if strings.HasPrefix(command, "RESCHEDULE_REMINDER_") {
+			promptReminderEdit("RESCHEDULE_"+strings.TrimPrefix(command, "RESCHEDULE_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "SKIP_REMINDER_") {
+			handleSkipReminder(strings.TrimPrefix(command, "SKIP_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "CANCEL_REMINDER_") {
+			handleCancelReminder(strings.TrimPrefix(command, "CANCEL_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "DELETE_REMINDER_") {
+			handleDeleteReminder(strings.TrimPrefix(command, "DELETE_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "CONFIRM_DELETE_REMINDER_") {
+			handleConfirmDeleteReminder(strings.TrimPrefix(command, "CONFIRM_DELETE_REMINDER_"), psid, token)
+		} else if strings.HasPrefix(command, "KEEP_REMINDER_") {
+			utils.SendTextMessage("Okay, I'll keep that reminder.", psid, token)
+		} else if strings.HasPrefix(command, "UNDO_SPLIT_") {
+			handleUndoSplit(strings.TrimPrefix(command, "UNDO_SPLIT_"), psid, token)
+		} else if strings.HasPrefix(command, "SHARE_EXPENSE_") {
+			handleShareExpense(strings.TrimPrefix(command, "SHARE_EXPENSE_"), psid, token)
+		} else if strings.HasPrefix(command, "UNSHARE_EXPENSE_") {
+			handleUnshareExpense(strings.TrimPrefix(command, "UNSHARE_EXPENSE_"), psid, token)
+		} else if strings.HasPrefix(command, "VIEW_DEBT_") {
+			handleViewDebt(strings.TrimPrefix(command, "VIEW_DEBT_"), psid, token)
+		} else if strings.HasPrefix(command, "STOP_RECURRING_") {
+			handleStopRecurring(strings.TrimPrefix(command, "STOP_RECURRING_"), psid, token)
+		} else if strings.HasPrefix(command, "ADD_RECEIPT_") {
+			handleAddReceipt(strings.TrimPrefix(command, "ADD_RECEIPT_"), psid, token)
+		} else if strings.HasPrefix(command, "VIEW_RECEIPTS_") {
+			sendExpenseReceipts(strings.TrimPrefix(command, "VIEW_RECEIPTS_"), psid, token)
+		} else if strings.HasPrefix(command, "KEEP_EXPENSE_") {
+			utils.SendTextMessage("Okay, I'll keep that expense as logged.", psid, token)
+		} else if strings.HasPrefix(command, "UNDO_EXPENSE_") {
+			expenseID := strings.TrimPrefix(command, "UNDO_EXPENSE_")
+			err := api.DeleteExpenseByID(psid, expenseID)
+			if err != nil {
+				fmt.Printf("Error deleting expense %s for user %s: %v\n", expenseID, psid, err)
+				utils.SendTextMessage("Sorry, I couldn't remove that expense.", psid, token)
+			} else {
+				utils.SendTextMessage("Done! That expense has been removed. You can log it again with the correct amount.", psid, token)
+			}
+		} else if strings.HasPrefix(command, "SET_SENSITIVITY_") {
+			level := strings.ToLower(strings.TrimPrefix(command, "SET_SENSITIVITY_"))
+			err := api.UpdateAnomalySensitivity(psid, level)
+			if err != nil {
+				fmt.Printf("Error updating alert sensitivity for user %s: %v\n", psid, err)
+				utils.SendTextMessage("Sorry, I couldn't update your alert sensitivity.", psid, token)
+			} else if level == "off" {
+				utils.SendTextMessage("Unusual expense alerts are now turned off.", psid, token)
+			} else {
+				utils.SendTextMessage(fmt.Sprintf("Unusual expense alert sensitivity set to %s.", level), psid, token)
 			}
 		} else if strings.HasPrefix(command, "VIEW_ACCOMPLISHED_DETAIL_") {
 			reminderID := strings.TrimPrefix(command, "VIEW_ACCOMPLISHED_DETAIL_")
@@ -73,13 +219,14 @@ func ProcessMainCommand(command, psid, mid, token string) {
 				fmt.Printf("Error fetching reminder details for reminder %s, user %s: %v\n", reminderID, psid, err)
 				utils.SendTextMessage("Sorry, I couldn't find the details for that payment.", psid, token)
 			} else {
-				detailsMessage := fmt.Sprintf("Details for your payment to %s:\nAmount: ₱%.2f\nGCash: %s\nDue Date: %s\nStatus: %s",
-					reminder.Recipient, reminder.Amount, reminder.GcashNumber, reminder.DueDate.Format("2006-01-02"), reminder.Status)
+				detailsMessage := fmt.Sprintf("Details for your payment to %s:\nAmount: ₱%.2f\n%s\nDue Date: %s\nStatus: %s",
+					reminder.Recipient, reminder.Amount, utils.DescribePaymentAccount(*reminder), utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet), reminder.Status)
 				utils.SendTextMessage(detailsMessage, psid, token)
+				sendPaymentProofs(*reminder, psid, token)
 			}
 		} else {
 			fmt.Printf("Unknown command: %s\n", command)
-			utils.SendGenerateRequest(templates.MenuTemplate[1], psid, token)
+			SendMainMenu(psid, token)
 		}
 	}
 }
@@ -87,41 +234,17 @@ func ProcessMainCommand(command, psid, mid, token string) {
 func ProcessTextMessageSent(command, psid, mid, token string) {
 	switch command {
 	case "LOG_EXPENSE_MESSAGE":
-		message := "Please log in this format: \n[amount] for [item/service]\n(e.g. 200.00 for softdrinks)"
+		message := "Please log in this format: \n[amount] for [item/service]\n(e.g. 200.00 for softdrinks)\n\nShared it? Add \"split with [names]\" (e.g. 1200 for lunch split with ana, ben) and type \"who owes me\" to see balances.\nFor fixed costs like rent, \"recurring 15000 for rent on 06/01 every month\" logs them for you.\nGot a receipt? Tap \"Add receipt\" after logging and send its photo, or type \"receipts\" to see the ones you kept.\nType \"history\" to see past expenses or \"search [text]\" to find one, or \"household\" to share expenses with the people you live with."
 		utils.SendTextMessage(message, psid, token)
-		userState[psid] = "RECORDING_EXPENSE_LOG"
+		setUserState(psid, "RECORDING_EXPENSE_LOG")
 	case "REPORT_LOG_DAY":
-		expenses, err := api.GetExpensesByUserAndRange(psid, "day")
-		if err != nil {
-			fmt.Printf("Error fetching daily expenses for user %s: %v\n", psid, err)
-			utils.SendTextMessage("Sorry, I couldn't fetch your expense report at the moment. Please try again later.", psid, token)
-			return
-		}
-
-		report := utils.GetExpenseReport(expenses, "Daily")
-		utils.SendTextMessage(report, psid, token)
+		SendExpenseReport("day", 0, psid, token)
 	case "REPORT_LOG_WEEK":
-		expenses, err := api.GetExpensesByUserAndRange(psid, "week")
-		if err != nil {
-			fmt.Printf("Error fetching weekly expenses for user %s: %v\n", psid, err)
-			utils.SendTextMessage("Sorry, I couldn't fetch your expense report at the moment. Please try again later.", psid, token)
-			return
-		}
-
-		report := utils.GetExpenseReport(expenses, "Weekly")
-		utils.SendTextMessage(report, psid, token)
+		SendExpenseReport("week", 0, psid, token)
 	case "REPORT_LOG_MONTH":
-		expenses, err := api.GetExpensesByUserAndRange(psid, "month")
-		if err != nil {
-			fmt.Printf("Error fetching monthly expenses for user %s: %v\n", psid, err)
-			utils.SendTextMessage("Sorry, I couldn't fetch your expense report at the moment. Please try again later.", psid, token)
-			return
-		}
-
-		report := utils.GetExpenseReport(expenses, "Monthly")
-		utils.SendTextMessage(report, psid, token)
+		SendExpenseReport("month", 0, psid, token)
 	case "VIEW_PENDING_PAYMENTS_MESSAGE":
-		reminders, err := api.GetReminders(psid, "pending")
+		reminders, err := api.GetReminders(psid, models.ReminderPending)
 		if err != nil {
 			fmt.Printf("Error fetching pending reminders for user %s: %v\n", psid, err)
 			utils.SendTextMessage("Sorry, I couldn't fetch your payment reminders at the moment. Please try again later.", psid, token)
@@ -135,8 +258,20 @@ func ProcessTextMessageSent(command, psid, mid, token string) {
 				// Optionally, send a text message to the user about the specific failure
 			}
 		}
+	case "VIEW_OVERDUE_PAYMENTS_MESSAGE":
+		reminders, err := api.GetReminders(psid, models.ReminderOverdue)
+		if err != nil {
+			fmt.Printf("Error fetching overdue reminders for user %s: %v\n", psid, err)
+			utils.SendTextMessage("Sorry, I couldn't fetch your payment reminders at the moment. Please try again later.", psid, token)
+			return
+		}
+		if len(reminders) == 0 {
+			utils.SendTextMessage("You don't have any overdue payments. Nice!", psid, token)
+			return
+		}
+		sendReminderCarousels(utils.GetRemindersReport(reminders), "overdue", psid, token)
 	case "VIEW_ACCOMPLISHED_PAYMENTS_MESSAGE":
-		reminders, err := api.GetReminders(psid, "completed")
+		reminders, err := api.GetReminders(psid, models.ReminderPaid)
 		if err != nil {
 			fmt.Printf("Error fetching accomplished reminders for user %s: %v\n", psid, err)
 			utils.SendTextMessage("Sorry, I couldn't fetch your payment reminders at the moment. Please try again later.", psid, token)
@@ -146,14 +281,7 @@ func ProcessTextMessageSent(command, psid, mid, token string) {
 			utils.SendTextMessage("You don't have any accomplished payments yet.", psid, token)
 			return
 		}
-		// Now call GetRemindersReport only if there are reminders
-		report := utils.GetRemindersReport(reminders)
-		// Send as a carousel
-		err = utils.SendTemplateMessage(report, psid, token)
-		if err != nil {
-			fmt.Printf("Error sending accomplished payments carousel for user %s: %v\n", psid, err)
-			utils.SendTextMessage("Sorry, I couldn't display your accomplished payments at the moment.", psid, token)
-		}
+		sendReminderCarousels(utils.GetRemindersReport(reminders), "accomplished", psid, token)
 	case "SET_REPORT_SCHED_MESSAGE":
 		message := "The report scheduling feature is not yet implemented. Please check back later!"
 		utils.SendTextMessage(message, psid, token)
@@ -173,19 +301,43 @@ func ProcessTextMessageSent(command, psid, mid, token string) {
 		message := "All your expense and reminder logs have been reset."
 		utils.SendTextMessage(message, psid, token)
 	case "SET_REMINDER_MESSAGE":
-		message := "Please set the reminder in this format: \n[amount] to [name]:[gcash number] on [month/day/year]\n(e.g. 200.00 to mark:09565546*** on 04/25/2025)"
+		message := "Please set the reminder in this format: \n[amount] to [name]:[gcash number] on [month/day/year]\n(e.g. 200.00 to mark:09565546*** on 04/25/2025 at 9am)\n\nFor other payment methods use \"via\": 2000 to landlord via bdo 0012345678 on 06/01/2025, or via maya [number], via bpi pesonet [account], via cash, via card. The time is optional. For bills that repeat, add a schedule such as \"every month\", \"every 2 weeks\", \"every second friday\", \"monthly on the last day\", \"every year\" or \"monthly until 12/2025\". Add \"notify 3,1 days before\" for advance notices, or type \"notify me 3,1 days before\", \"remind me at 8am\" or \"timezone Asia/Manila\" anytime to change your defaults.\n\nPay people often? Save them with \"add payee mark via gcash 09565546***\", then just type \"pay mark 200 on 04/25\". Type \"payees\" to see your list."
 		utils.SendTextMessage(message, psid, token)
-		userState[psid] = "RECORDING_REMINDER"
-	case "SUBSCRIPTION_STATUS_MESSAGE":
-		message := "The subscription status feature is not yet implemented. Please check back later!"
+		setUserState(psid, "RECORDING_REMINDER")
+	case "EXPENSE_ALERTS_SETTINGS_MESSAGE":
+		sensitivity := "medium"
+		preference, err := api.GetUserPreference(psid)
+		if err != nil {
+			fmt.Printf("Error fetching preferences for user %s: %v\n", psid, err)
+		} else {
+			sensitivity = preference.AnomalySensitivity
+		}
+		message := fmt.Sprintf("I'll warn you when an expense looks unusual for its category. Current sensitivity: %s.\nHow sensitive should the alerts be?", sensitivity)
+		utils.SendQuickReplies(message, templates.SensitivityQuickReplies, psid, token)
+	case "NEW_GOAL_MESSAGE":
+		message := "Please set your goal in this format: \ngoal [amount] for [name] by [month/year]\n(e.g. goal 30000 for laptop by 12/2025)"
+		utils.SendTextMessage(message, psid, token)
+	case "ADD_GOAL_SAVINGS_MESSAGE":
+		message := "Please log your savings in this format: \nsave [amount] to [goal name]\n(e.g. save 500 to laptop)"
 		utils.SendTextMessage(message, psid, token)
+	case "SUBSCRIPTION_STATUS_MESSAGE":
+		sendSubscriptionStatus(psid, token)
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 	}
 }
 
 func ProcessTextMessageReceived(message, psid, mid, token string) {
-	state, exists := userState[psid]
+	// A reminder edit takes the next message as the new value, even one that reads like a command
+	if state, _ := getUserState(psid); state == "EDITING_REMINDER" {
+		handleReminderEditInput(message, psid, token)
+		return
+	}
+	if ProcessTextCommand(message, psid, mid, token) {
+		return
+	}
+
+	state, exists := getUserState(psid)
 	if exists {
 		switch state {
 		case "RECORDING_EXPENSE_LOG":
@@ -194,64 +346,94 @@ func ProcessTextMessageReceived(message, psid, mid, token string) {
 				if err != nil {
 					fmt.Printf("Error parsing expense data for user %s: %v\n", psid, err)
 					utils.SendTextMessage("There was an issue parsing your expense. Please ensure you're using the format: [amount] for [item/service]", psid, token)
-					userState[psid] = "WAITING..." // Reset state as format was correct
+					setUserState(psid, "WAITING...") // Reset state as format was correct
 					return
 				}
 
-				err = api.SaveExpense(amount, category, psid)
+				// The baseline is taken before saving so the new expense is not compared against itself.
+				baseline, baselineErr := api.GetCategoryBaseline(psid, category)
+				if baselineErr != nil {
+					fmt.Printf("Error computing category baseline for user %s: %v\n", psid, baselineErr)
+				}
+
+				expense, err := api.SaveExpense(amount, category, psid)
 				if err != nil {
 					fmt.Printf("Error saving expense for user %s: %v\n", psid, err)
 					utils.SendTextMessage("Sorry, I couldn't save your expense. Please try again later.", psid, token)
-					userState[psid] = "WAITING..." // Reset state as format was correct
+					setUserState(psid, "WAITING...") // Reset state as format was correct
 					return
 				}
 				currentTime = time.Now()
 				message_ := fmt.Sprintf("Got it! You spent ₱%.2f on %s on %s", amount, category, currentTime.Format("Jan 2, 2006 at 3:04 PM"))
-				utils.SendTextMessage(message_, psid, token)
+				sendExpenseSaved(expense, message_, psid, token)
 				fmt.Printf("Expense saved for user %s: ₱%.2f on %s\n", psid, amount, category)
-				userState[psid] = "WAITING..."
+				if baselineErr == nil {
+					WarnIfUnusualExpense(expense, baseline, psid, token)
+				}
+				setUserState(psid, "WAITING...")
 			} else {
 				message = "Invalid Format. Please try again."
 				utils.SendTextMessage(message, psid, token)
 				ProcessMainCommand("GET_STARTED", psid, mid, token)
-				userState[psid] = "WAITING..."
+				setUserState(psid, "WAITING...")
 			}
 		case "RECORDING_REMINDER":
-			if utils.IsReminderLogFormatCorrect(message) {
-				amount, accountName, gcashNumber, dueDate, err := utils.GetReminderDataFromMessage(message)
+			message, leadDays := utils.SplitLeadDaysFromMessage(message)
+			message, rule, recurrenceErr := utils.SplitRecurrenceFromMessage(message)
+			frequency, rrule := "once", ""
+			if rule != nil {
+				frequency, rrule = utils.RuleFrequency(*rule), rule.String()
+			}
+			if recurrenceErr == nil && utils.IsReminderLogFormatCorrect(message) {
+				details, err := utils.GetReminderDataFromMessage(message)
 				if err != nil {
 					fmt.Printf("Error parsing reminder data for user %s: %v\n", psid, err)
-					utils.SendTextMessage("There was an issue parsing your reminder. Please ensure you're using the format: [amount] to [name]:[gcash number] on [month/day/year]", psid, token)
-					userState[psid] = "WAITING..." // Reset state as format was correct
+					utils.SendTextMessage(fmt.Sprintf("There was an issue with your reminder: %v. Please use the format: [amount] to [name] via [payment method] [account] on [month/day/year]", err), psid, token)
+					setUserState(psid, "WAITING...") // Reset state as format was correct
 					return
 				}
 
-				err = api.SaveReminder(psid, amount, accountName, gcashNumber, dueDate, "Gcash", "pending", "payment", "once")
+				amount, dueDate, hasTime := details.Amount, details.DueDate, details.HasTime
+				err = api.SaveReminder(psid, amount, details.Recipient, details.Payment, dueDate, "payment", frequency, leadDays, hasTime, rrule)
 				if err != nil {
 					fmt.Printf("Error saving reminder for user %s: %v\n", psid, err)
 					utils.SendTextMessage("Sorry, I couldn't save your reminder. Please try again later.", psid, token)
-					userState[psid] = "WAITING..." // Reset state as format was correct
+					setUserState(psid, "WAITING...") // Reset state as format was correct
 					return
 				}
-				message_ := fmt.Sprintf("Reminder: Pay ₱%.2f to %s (%s) on %s", amount, accountName, gcashNumber, dueDate.Format("01/02/2006"))
-				utils.SendTextMessage(message_, psid, token)
-				fmt.Printf("Reminder saved for user %s: ₱%.2f to %s (%s) on %s\n", psid, amount, accountName, gcashNumber, dueDate.Format("01/02/2006"))
-				userState[psid] = "WAITING..."
+				sendReminderSaved(details, rule, leadDays, psid, token)
+				setUserState(psid, "WAITING...")
 			} else {
-				message = "Invalid Format. Follow the format or verify the Gcash Number. Please try again"
+				message = "Invalid Format. Follow the format or verify the account number. Please try again"
 				utils.SendTextMessage(message, psid, token)
 				ProcessMainCommand("GET_STARTED", psid, mid, token)
-				userState[psid] = "WAITING..."
+				setUserState(psid, "WAITING...")
 			}
 		default:
 			message = "Your input cannot be processed. Please select an option from the menu."
 			utils.SendTextMessage(message, psid, token)
-			utils.SendGenerateRequest(templates.MenuTemplate[1], psid, token)
+			SendMainMenu(psid, token)
 		}
 	} else {
 		message = "Your input cannot be processed. Please select an option from the menu."
 		utils.SendTextMessage(message, psid, token)
-		utils.SendGenerateRequest(templates.MenuTemplate[1], psid, token)
+		SendMainMenu(psid, token)
 	}
 
 }
+
+// sendReminderCarousels sends reminder cards as carousels of at most maxCarouselElements each.
+// label names the list in logs and errors, e.g. "overdue".
+func sendReminderCarousels(cards []templates.Template, label string, psid, token string) {
+	for start := 0; start < len(cards); start += maxCarouselElements {
+		end := start + maxCarouselElements
+		if end > len(cards) {
+			end = len(cards)
+		}
+		if err := utils.SendTemplateMessage(cards[start:end], psid, token); err != nil {
+			fmt.Printf("Error sending %s payments carousel for user %s: %v\n", label, psid, err)
+			utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't display your %s payments at the moment.", label), psid, token)
+			return
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+)
+
+// SendMainMenu sends the top-level menu as a carousel of the MenuTemplate entries in templates.MainMenuKeys.
+func SendMainMenu(psid, token string) error {
+	var elements []templates.Template
+	for _, key := range templates.MainMenuKeys {
+		elements = append(elements, templates.MenuTemplate[key])
+	}
+	return utils.SendTemplateMessage(elements, psid, token)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/utils"
+)
+
+func sendPayees(psid, token string) {
+	payees, err := api.GetPayees(psid)
+	if err != nil {
+		fmt.Printf("Error fetching payees for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your payees at the moment. Please try again later.", psid, token)
+		return
+	}
+	for _, chunk := range utils.SplitMessage(utils.GetPayeesReport(payees), utils.MessengerTextLimit) {
+		utils.SendTextMessage(chunk, psid, token)
+	}
+}
+
+func handleAddPayee(message, psid, token string) {
+	input, err := utils.GetPayeeDataFromMessage(message)
+	if err == nil && input.Payment == nil {
+		err = fmt.Errorf("payment details are missing")
+	}
+	if err != nil {
+		fmt.Printf("Error parsing payee for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Please use the format: add payee [name] via [payment method] [account] default [amount]\n(e.g. add payee landlord via bdo 0012345678 default 15000)", err), psid, token)
+		return
+	}
+
+	payee, err := api.SavePayee(psid, input.Name, *input.Payment, input.DefaultAmount)
+	if err != nil {
+		fmt.Printf("Error saving payee for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't add that payee: %v", err), psid, token)
+		return
+	}
+
+	reply := fmt.Sprintf("Payee saved: %s (%s)", payee.Name, utils.DescribePaymentDetails(*input.Payment))
+	if payee.DefaultAmount != nil {
+		reply += fmt.Sprintf(", usually ₱%.2f", *payee.DefaultAmount)
+	}
+	reply += fmt.Sprintf(".\nSet a reminder with: pay %s [amount] on [month/day]", payee.Name)
+	utils.SendTextMessage(reply, psid, token)
+}
+
+func handleEditPayee(message, psid, token string) {
+	input, err := utils.GetPayeeDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing payee update for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Please use the format: edit payee [name] via [payment method] [account] and/or default [amount]", err), psid, token)
+		return
+	}
+
+	payee, err := api.UpdatePayee(psid, input.Name, api.PayeeUpdate{
+		Payment:            input.Payment,
+		DefaultAmount:      input.DefaultAmount,
+		ClearDefaultAmount: input.ClearDefaultAmount,
+	})
+	if errors.Is(err, api.ErrPayeeNotFound) {
+		utils.SendTextMessage(fmt.Sprintf("I couldn't find a payee named %s. Type \"payees\" to see your list.", input.Name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error updating payee for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't update that payee: %v", err), psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Payee %s updated. Pending reminders for %s now use the new details.", payee.Name, payee.Name), psid, token)
+}
+
+func handleRenamePayee(message, psid, token string) {
+	name, newName, err := utils.GetRenamePayeeDataFromMessage(message)
+	if err == nil {
+		_, err = api.UpdatePayee(psid, name, api.PayeeUpdate{Name: &newName})
+	}
+	if errors.Is(err, api.ErrPayeeNotFound) {
+		utils.SendTextMessage(fmt.Sprintf("I couldn't find a payee named %s. Type \"payees\" to see your list.", name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error renaming payee for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't rename that payee: %v", err), psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Payee %s renamed to %s.", name, newName), psid, token)
+}
+
+func handleRemovePayee(message, psid, token string) {
+	name, err := utils.GetRemovePayeeNameFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage("Please use the format: remove payee [name]", psid, token)
+		return
+	}
+
+	payee, err := api.DeletePayee(psid, name)
+	if errors.Is(err, api.ErrPayeeNotFound) {
+		utils.SendTextMessage(fmt.Sprintf("I couldn't find a payee named %s. Type \"payees\" to see your list.", name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error removing payee for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't remove that payee.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Payee %s removed. Existing reminders for them are kept.", payee.Name), psid, token)
+}
+
+// handlePayCommand sets a payment reminder for a saved payee, e.g. "pay mark 2000 on 06/01".
+// Like a typed reminder, it may end with a recurrence and advance notice.
+func handlePayCommand(message, psid, token string) {
+	message, leadDays := utils.SplitLeadDaysFromMessage(message)
+	message, rule, err := utils.SplitRecurrenceFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I didn't understand the schedule: %v", err), psid, token)
+		return
+	}
+	frequency, rrule := "once", ""
+	if rule != nil {
+		frequency, rrule = utils.RuleFrequency(*rule), rule.String()
+	}
+
+	name, amount, dueDate, hasTime, err := utils.GetPayCommandDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing pay command for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Please use the format: pay [payee] [amount] on [month/day]\n(e.g. pay mark 2000 on 06/01)", psid, token)
+		return
+	}
+
+	payee, err := api.GetPayeeByName(psid, name)
+	if errors.Is(err, api.ErrPayeeNotFound) {
+		utils.SendTextMessage(fmt.Sprintf("I don't have a payee named %s yet. Add them with: add payee %s via [payment method] [account]", name, name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error fetching payee %s for user %s: %v\n", name, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't look up that payee. Please try again later.", psid, token)
+		return
+	}
+
+	if amount == nil {
+		amount = payee.DefaultAmount
+	}
+	if amount == nil {
+		utils.SendTextMessage(fmt.Sprintf("How much should I remind you to pay %s? e.g. pay %s 2000 on 06/01", payee.Name, payee.Name), psid, token)
+		return
+	}
+
+	details := utils.ReminderDetails{
+		Amount:    *amount,
+		Recipient: payee.Name,
+		Payment: utils.PaymentDetails{
+			Method:        payee.PaymentMethod,
+			AccountNumber: payee.AccountNumber,
+			BankName:      payee.BankName,
+			TransferRail:  payee.TransferRail,
+		},
+		DueDate: dueDate,
+		HasTime: hasTime,
+	}
+	err = api.SaveReminder(psid, details.Amount, details.Recipient, details.Payment, dueDate, "payment", frequency, leadDays, hasTime, rrule)
+	if errors.Is(err, api.ErrInvalidPaymentDetails) {
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Update the payee with: edit payee %s via [payment method] [account]", err, payee.Name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error saving reminder for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save your reminder. Please try again later.", psid, token)
+		return
+	}
+
+	sendReminderSaved(details, rule, leadDays, psid, token)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strings"
+	"time"
+)
+
+// maxQuickReplyChoices leaves room for one more option, such as "Not a payment", within
+// Messenger's limit of 13 quick replies.
+const maxQuickReplyChoices = 12
+
+// maxQuickReplyTitle is the longest quick reply title Messenger shows in full.
+const maxQuickReplyTitle = 20
+
+// ProcessAttachmentsReceived handles images a user sends. After tapping "Add receipt" or
+// replying "receipt", they are attached to that expense as receipts. Otherwise each image is
+// stored and the user is asked which reminder it is the payment proof for.
+func ProcessAttachmentsReceived(urls []string, psid, mid, token string) {
+	fmt.Printf("Processing %d attachment(s), PSID: %s, MID: %s\n", len(urls), psid, mid)
+	if expenseID, ok := takeReceiptTarget(psid); ok {
+		for _, url := range urls {
+			handleReceiptUpload(url, expenseID, psid, token)
+		}
+		return
+	}
+	for _, url := range urls {
+		handlePaymentProofUpload(url, psid, token)
+	}
+}
+
+func handlePaymentProofUpload(url string, psid, token string) {
+	image, contentType, err := utils.DownloadAttachment(url)
+	if err == nil && !strings.HasPrefix(contentType, "image/") {
+		err = fmt.Errorf("unsupported content type %s", contentType)
+	}
+	if err != nil {
+		fmt.Printf("Error downloading attachment for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save that image. Please try sending it again.", psid, token)
+		return
+	}
+
+	reminders, err := api.GetActiveReminders(psid, maxQuickReplyChoices)
+	if err != nil {
+		fmt.Printf("Error fetching active reminders for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save that image. Please try again later.", psid, token)
+		return
+	}
+	if len(reminders) == 0 {
+		utils.SendTextMessage("Thanks for the image! You don't have any pending payments to attach it to as proof. If it's a receipt, reply \"receipt\" and send it again to attach it to your latest expense.", psid, token)
+		return
+	}
+
+	proof, err := api.SavePaymentProof(psid, image, contentType)
+	if err != nil {
+		fmt.Printf("Error saving payment proof for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save that image. Please try again later.", psid, token)
+		return
+	}
+
+	message := "Got your image! Which payment is it the proof for? I'll mark it as paid."
+	var quickReplies []templates.QuickReply
+	for _, reminder := range reminders {
+		message += fmt.Sprintf("\n• ₱%.2f to %s, due %s", reminder.Amount, reminder.Recipient, utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text",
+			Title:       quickReplyTitle(fmt.Sprintf("₱%.0f %s", reminder.Amount, reminder.Recipient)),
+			Payload:     fmt.Sprintf("ATTACH_PROOF_%d_%d", proof.ID, reminder.ID),
+		})
+	}
+	quickReplies = append(quickReplies, templates.QuickReply{
+		ContentType: "text",
+		Title:       "Not a payment",
+		Payload:     fmt.Sprintf("DISCARD_PROOF_%d", proof.ID),
+	})
+
+	if err := utils.SendQuickReplies(message, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending payment proof prompt for user %s: %v\n", psid, err)
+	}
+}
+
+// handleAttachProof links an uploaded proof to a reminder and marks it paid.
+// Payload format: <proofID>_<reminderID>
+func handleAttachProof(payload string, psid, token string) {
+	parts := strings.SplitN(payload, "_", 2)
+	if len(parts) != 2 {
+		fmt.Printf("Malformed attach proof payload: %s\n", payload)
+		return
+	}
+
+	occurrence, reminder, err := api.AttachPaymentProof(psid, parts[0], parts[1], time.Now())
+	if errors.Is(err, api.ErrProofAlreadyAttached) {
+		utils.SendTextMessage("That image is already attached to a payment.", psid, token)
+		return
+	}
+	if errors.Is(err, api.ErrInvalidStatusTransition) {
+		utils.SendTextMessage("That reminder is no longer pending, so I couldn't attach the proof to it.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error attaching proof %s to reminder %s for user %s: %v\n", parts[0], parts[1], psid, err)
+		utils.SendTextMessage("Sorry, I couldn't attach that proof.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Proof attached to your payment to %s. You can view it from Accomplished Payments.", reminder.Recipient), psid, token)
+	confirmPayment(occurrence, reminder, psid, token)
+}
+
+func handleDiscardProof(proofID string, psid, token string) {
+	if err := api.DiscardPaymentProof(psid, proofID); err != nil {
+		fmt.Printf("Error discarding proof %s for user %s: %v\n", proofID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't remove that image.", psid, token)
+		return
+	}
+	utils.SendTextMessage("Okay, I won't keep that image.", psid, token)
+}
+
+// sendPaymentProofs sends the proof images attached to a reminder.
+func sendPaymentProofs(reminder models.RemindersLog, psid, token string) {
+	proofs, err := api.GetReminderPaymentProofs(psid, fmt.Sprint(reminder.ID))
+	if err != nil {
+		fmt.Printf("Error fetching payment proofs for reminder %d, user %s: %v\n", reminder.ID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch the payment proof.", psid, token)
+		return
+	}
+	if len(proofs) == 0 {
+		utils.SendTextMessage("No payment proof attached. Send a screenshot of your receipt to attach one to a pending payment.", psid, token)
+		return
+	}
+
+	for _, proof := range proofs {
+		image, err := api.GetPaymentProofImage(proof)
+		if err != nil {
+			fmt.Printf("Error reading payment proof %d for user %s: %v\n", proof.ID, psid, err)
+			continue
+		}
+		filename := fmt.Sprintf("proof-%d%s", proof.ID, proofExtension(proof.BlobKey))
+		if err := utils.SendImageAttachment(image, filename, proof.ContentType, psid, token); err != nil {
+			fmt.Printf("Error sending payment proof %d for user %s: %v\n", proof.ID, psid, err)
+		}
+	}
+}
+
+// proofExtension returns the file extension of a blob key, if it has one.
+func proofExtension(key string) string {
+	if i := strings.LastIndex(key, "."); i > strings.LastIndex(key, "/") {
+		return key[i:]
+	}
+	return ""
+}
+
+// quickReplyTitle shortens a title to what Messenger shows on a quick reply.
+func quickReplyTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= maxQuickReplyTitle {
+		return title
+	}
+	return string(runes[:maxQuickReplyTitle-1]) + "…"
+}
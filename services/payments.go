@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strings"
+)
+
+// confirmPayment tells the user a reminder payment was recorded. If the payment was logged as an
+// expense an undo option is offered; otherwise the user is asked which category to log it under.
+func confirmPayment(occurrence *models.ReminderOccurrence, reminder *models.RemindersLog, psid, token string) {
+	message := "Payment has been marked as paid."
+	if reminder.Status != models.ReminderPaid {
+		message = fmt.Sprintf("Payment due %s has been marked as paid. Next payment is due %s.",
+			utils.FormatDueDate(occurrence.DueDate, reminder.DueTimeSet), utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+	}
+
+	undo := templates.QuickReply{ContentType: "text", Title: "Undo payment", Payload: fmt.Sprintf("UNDO_PAYMENT_%d", occurrence.ID)}
+
+	var quickReplies []templates.QuickReply
+	if occurrence.ExpenseID != nil {
+		message += fmt.Sprintf(" Logged ₱%.2f under %s.", reminder.Amount, reminder.Category)
+	} else {
+		message += fmt.Sprintf(" Which category should I log the ₱%.2f expense under?", reminder.Amount)
+		for _, category := range templates.PaymentCategories {
+			quickReplies = append(quickReplies, templates.QuickReply{
+				ContentType: "text",
+				Title:       category,
+				Payload:     fmt.Sprintf("PAID_CATEGORY_%d_%s", occurrence.ID, category),
+			})
+		}
+	}
+	quickReplies = append(quickReplies, undo)
+
+	err := utils.SendQuickReplies(message, quickReplies, psid, token)
+	if err != nil {
+		fmt.Printf("Error sending payment confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+// handlePaymentCategory logs a paid occurrence as an expense. Payload format: <occurrenceID>_<category>
+func handlePaymentCategory(payload string, psid, token string) {
+	parts := strings.SplitN(payload, "_", 2)
+	if len(parts) != 2 {
+		fmt.Printf("Malformed payment category payload: %s\n", payload)
+		return
+	}
+
+	category, ok := paymentCategoryName(parts[1])
+	if !ok {
+		fmt.Printf("Unknown payment category in payload: %s\n", payload)
+		utils.SendTextMessage("Sorry, I don't know that category.", psid, token)
+		return
+	}
+
+	expense, err := api.LogReminderPaymentExpense(psid, parts[0], category)
+	if err != nil {
+		fmt.Printf("Error logging payment %s as an expense for user %s: %v\n", parts[0], psid, err)
+		utils.SendTextMessage("Sorry, I couldn't log that payment as an expense.", psid, token)
+		return
+	}
+
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Undo payment", Payload: "UNDO_PAYMENT_" + parts[0]},
+	}
+	message := fmt.Sprintf("Logged ₱%.2f under %s. Future payments for this reminder will use the same category.", expense.Amount, expense.Category)
+	if err := utils.SendQuickReplies(message, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending payment expense confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+// paymentCategoryName returns the category as it's listed in templates.PaymentCategories.
+// Payloads arrive uppercased, so "BILLS" is logged as "Bills".
+func paymentCategoryName(category string) (string, bool) {
+	for _, name := range templates.PaymentCategories {
+		if strings.EqualFold(name, category) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// handleUndoPayment reverts a reminder payment and removes the expense that was logged for it.
+func handleUndoPayment(occurrenceID string, psid, token string) {
+	reminder, err := api.UndoReminderPayment(psid, occurrenceID)
+	if err != nil {
+		fmt.Printf("Error undoing payment %s for user %s: %v\n", occurrenceID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't undo that payment.", psid, token)
+		return
+	}
+
+	message := fmt.Sprintf("Payment undone. Your reminder for %s is pending again, due %s.",
+		reminder.Recipient, utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+	utils.SendTextMessage(message, psid, token)
+}
+
+// sendPaymentInstructions tells the user how to pay a reminder with its payment method, with a
+// pre-filled QR Ph code when the method supports one.
+func sendPaymentInstructions(reminderID string, psid, token string) {
+	reminder, qrCode, err := api.GetReminderPaymentQR(psid, reminderID)
+	if err != nil {
+		fmt.Printf("Error preparing payment for reminder %s, user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't prepare the payment details.", psid, token)
+		return
+	}
+
+	method := utils.GetPaymentMethod(reminder.PaymentMethod)
+	message := fmt.Sprintf("Pay ₱%.2f to %s\n%s", reminder.Amount, reminder.Recipient, utils.DescribePaymentAccount(*reminder))
+	if method.Key == "Bank" && reminder.TransferRail == utils.RailPESONet {
+		message += "\nPESONet transfers settle by the next banking day, so send it early."
+	}
+	if qrCode != nil {
+		message += fmt.Sprintf("\nScan or upload this QR code in %s or your banking app. The amount and account are already filled in.", method.Name)
+	}
+	utils.SendTextMessage(message, psid, token)
+
+	if qrCode != nil {
+		filename := fmt.Sprintf("%s-%s.png", strings.ToLower(method.Key), reminderID)
+		if err := utils.SendImageAttachment(qrCode, filename, "image/png", psid, token); err != nil {
+			fmt.Printf("Error sending payment QR code for reminder %s, user %s: %v\n", reminderID, psid, err)
+		}
+	}
+}
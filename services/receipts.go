@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// receiptWindow is how long after tapping "Add receipt" or typing "receipt" that the next
+// images the user sends are taken as that expense's receipt rather than as payment proof.
+const receiptWindow = 10 * time.Minute
+
+type receiptTarget struct {
+	ExpenseID uint
+	Until     time.Time
+}
+
+// receiptTargets keeps the expense each user's next images are attached to, while the window
+// lasts. Webhook events are handled concurrently, so access goes through receiptTargetsMu.
+var (
+	receiptTargets   = make(map[string]receiptTarget)
+	receiptTargetsMu sync.Mutex
+)
+
+func expectReceipt(expenseID uint, psid string) {
+	receiptTargetsMu.Lock()
+	defer receiptTargetsMu.Unlock()
+	receiptTargets[psid] = receiptTarget{ExpenseID: expenseID, Until: time.Now().Add(receiptWindow)}
+}
+
+// takeReceiptTarget returns the expense the user's images should be attached to, if any, and
+// clears it so later images go back to being payment proofs.
+func takeReceiptTarget(psid string) (uint, bool) {
+	receiptTargetsMu.Lock()
+	defer receiptTargetsMu.Unlock()
+
+	target, exists := receiptTargets[psid]
+	delete(receiptTargets, psid)
+	if !exists || time.Now().After(target.Until) {
+		return 0, false
+	}
+	return target.ExpenseID, true
+}
+
+// handleReceiptCommand gets ready to attach the next image to the user's latest expense.
+func handleReceiptCommand(psid, token string) {
+	expense, err := api.GetLatestExpense(psid)
+	if errors.Is(err, api.ErrExpenseNotFound) {
+		utils.SendTextMessage("You haven't logged any expenses yet. Log one first, then send the receipt photo.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error fetching latest expense for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't find your latest expense. Please try again later.", psid, token)
+		return
+	}
+	promptForReceipt(expense, psid, token)
+}
+
+// handleAddReceipt gets ready to attach the next image to the expense from an "Add receipt" reply.
+func handleAddReceipt(expenseID string, psid, token string) {
+	expense, _, err := api.GetExpenseReceipts(psid, expenseID)
+	if err != nil {
+		fmt.Printf("Error fetching expense %s for user %s: %v\n", expenseID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't find that expense. It may have been removed.", psid, token)
+		return
+	}
+	promptForReceipt(expense, psid, token)
+}
+
+func promptForReceipt(expense *models.ExpensesLog, psid, token string) {
+	expectReceipt(expense.ID, psid)
+	utils.SendTextMessage(fmt.Sprintf("Send a photo of the receipt for ₱%.2f on %s and I'll attach it.", expense.Amount, expense.Category), psid, token)
+}
+
+// handleReceiptUpload stores an image the user sent as a receipt for the expense.
+func handleReceiptUpload(url string, expenseID uint, psid, token string) {
+	image, contentType, err := utils.DownloadAttachment(url)
+	if err == nil && !strings.HasPrefix(contentType, "image/") {
+		err = fmt.Errorf("unsupported content type %s", contentType)
+	}
+	if err != nil {
+		fmt.Printf("Error downloading receipt for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save that receipt. Please try sending it again.", psid, token)
+		return
+	}
+
+	_, expense, err := api.SaveExpenseReceipt(psid, strconv.FormatUint(uint64(expenseID), 10), image, contentType)
+	if errors.Is(err, api.ErrExpenseNotFound) {
+		utils.SendTextMessage("That expense was removed, so I didn't keep the receipt.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error saving receipt for expense %d, user %s: %v\n", expenseID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save that receipt. Please try again later.", psid, token)
+		return
+	}
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "View receipts", Payload: fmt.Sprintf("VIEW_RECEIPTS_%d", expense.ID)},
+		{ContentType: "text", Title: "Add another", Payload: fmt.Sprintf("ADD_RECEIPT_%d", expense.ID)},
+	}
+	message := fmt.Sprintf("Receipt attached to ₱%.2f on %s. Type \"receipts\" anytime to see your receipts.", expense.Amount, expense.Category)
+	if err := utils.SendQuickReplies(message, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending receipt confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+// sendReceiptList lists the user's recent expenses that have receipts, with a quick reply to
+// view each one's photos.
+func sendReceiptList(psid, token string) {
+	expenses, receiptCounts, err := api.GetExpensesWithReceipts(psid, maxQuickReplyChoices)
+	if err != nil {
+		fmt.Printf("Error fetching receipts for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your receipts at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	message := utils.GetReceiptsReport(expenses, receiptCounts)
+	if len(expenses) == 0 {
+		utils.SendTextMessage(message, psid, token)
+		return
+	}
+
+	var quickReplies []templates.QuickReply
+	for _, expense := range expenses {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text",
+			Title:       quickReplyTitle(fmt.Sprintf("₱%.0f %s", expense.Amount, expense.Category)),
+			Payload:     fmt.Sprintf("VIEW_RECEIPTS_%d", expense.ID),
+		})
+	}
+	if err := utils.SendQuickReplies(message, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending receipts to user %s: %v\n", psid, err)
+	}
+}
+
+// sendExpenseReceipts sends the receipt photos attached to an expense.
+func sendExpenseReceipts(expenseID string, psid, token string) {
+	expense, receipts, err := api.GetExpenseReceipts(psid, expenseID)
+	if err != nil {
+		fmt.Printf("Error fetching receipts for expense %s, user %s: %v\n", expenseID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch the receipts for that expense.", psid, token)
+		return
+	}
+	if len(receipts) == 0 {
+		quickReplies := []templates.QuickReply{
+			{ContentType: "text", Title: "Add receipt", Payload: fmt.Sprintf("ADD_RECEIPT_%d", expense.ID)},
+		}
+		utils.SendQuickReplies(fmt.Sprintf("No receipts attached to ₱%.2f on %s.", expense.Amount, expense.Category), quickReplies, psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Receipts for ₱%.2f on %s, logged %s:", expense.Amount, expense.Category, expense.CreatedAt.Format("Jan 2, 2006")), psid, token)
+	for _, receipt := range receipts {
+		image, err := api.GetReceiptImage(receipt)
+		if err != nil {
+			fmt.Printf("Error reading receipt %d for user %s: %v\n", receipt.ID, psid, err)
+			continue
+		}
+		filename := fmt.Sprintf("receipt-%d%s", receipt.ID, proofExtension(receipt.BlobKey))
+		if err := utils.SendImageAttachment(image, filename, receipt.ContentType, psid, token); err != nil {
+			fmt.Printf("Error sending receipt %d for user %s: %v\n", receipt.ID, psid, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"time"
+)
+
+// LogDueRecurringExpenses records the fixed costs that have reached their date as expenses and
+// tells each user, with the option to undo. A date counts as reached at the user's reminder time.
+func LogDueRecurringExpenses() {
+	token := os.Getenv("PAGE_TOKEN")
+	if token == "" {
+		fmt.Println("Recurring Expenses: Error - PAGE_TOKEN not set. Cannot send messages.")
+		return
+	}
+
+	now := time.Now()
+	recurringExpenses, err := api.GetDueRecurringExpenses(now)
+	if err != nil {
+		fmt.Printf("Recurring Expenses: Error fetching due recurring expenses: %v\n", err)
+		return
+	}
+
+	preferences := make(map[string]*models.UserPreference)
+	for i := range recurringExpenses {
+		recurring := &recurringExpenses[i]
+		preference, err := loadPreference(preferences, recurring.UserID)
+		if err != nil {
+			fmt.Printf("Recurring Expenses: Error fetching preferences for user %s: %v\n", recurring.UserID, err)
+			continue
+		}
+		dueAt := utils.ReminderDueAt(*recurring.NextRunAt, false, preference.ReminderTime, api.UserLocation(preference))
+		if now.Before(dueAt) {
+			continue
+		}
+
+		// Dated when it was due, so a catch-up after downtime lands in the right period
+		expense, err := api.LogRecurringExpense(recurring, dueAt)
+		if errors.Is(err, api.ErrRecurringExpenseNotDue) {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Recurring Expenses: Error logging recurring expense ID %d: %v\n", recurring.ID, err)
+			continue
+		}
+		fmt.Printf("Recurring Expenses: Logged recurring expense ID %d as expense ID %d.\n", recurring.ID, expense.ID)
+
+		sendRecurringExpenseLogged(*recurring, expense, token)
+	}
+}
+
+func sendRecurringExpenseLogged(recurring models.RecurringExpense, expense *models.ExpensesLog, token string) {
+	message := fmt.Sprintf("Logged your recurring expense: ₱%.2f for %s.", expense.Amount, expense.Category)
+	if recurring.NextRunAt != nil {
+		message += fmt.Sprintf(" Next one is on %s.", utils.FormatDueDate(*recurring.NextRunAt, false))
+	} else {
+		message += " That was the last one on its schedule."
+	}
+
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Undo", Payload: fmt.Sprintf("UNDO_EXPENSE_%d", expense.ID)},
+	}
+	if recurring.NextRunAt != nil {
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text", Title: "Stop recurring", Payload: fmt.Sprintf("STOP_RECURRING_%d", recurring.ID),
+		})
+	}
+	if err := utils.SendQuickReplies(message, quickReplies, recurring.UserID, token); err != nil {
+		fmt.Printf("Recurring Expenses: Error notifying user %s: %v\n", recurring.UserID, err)
+	}
+}
+
+// handleRecurringExpense schedules a fixed cost, e.g. "recurring 15000 for rent on 06/01 every month".
+func handleRecurringExpense(message, psid, token string) {
+	amount, category, start, rule, err := utils.GetRecurringExpenseDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing recurring expense for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Please use the format: recurring [amount] for [item] on [month/day] every [schedule]\n(e.g. recurring 15000 for rent on 06/01 every month)", err), psid, token)
+		return
+	}
+
+	recurring, err := api.SaveRecurringExpense(psid, amount, category, start, rule)
+	if err != nil {
+		fmt.Printf("Error saving recurring expense for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't set that up: %v.", err), psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Done! I'll log ₱%.2f for %s automatically (%s), starting %s. Type \"recurring\" to see or stop your recurring expenses.",
+		recurring.Amount, recurring.Category, rule.Describe(), utils.FormatDueDate(*recurring.NextRunAt, false)), psid, token)
+}
+
+func sendRecurringExpenses(psid, token string) {
+	recurringExpenses, err := api.GetRecurringExpenses(psid)
+	if err != nil {
+		fmt.Printf("Error fetching recurring expenses for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your recurring expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	message := utils.GetRecurringExpensesReport(recurringExpenses)
+	var quickReplies []templates.QuickReply
+	for _, recurring := range recurringExpenses {
+		if len(quickReplies) == maxQuickReplyChoices {
+			break
+		}
+		quickReplies = append(quickReplies, templates.QuickReply{
+			ContentType: "text",
+			Title:       quickReplyTitle("Stop " + recurring.Category),
+			Payload:     fmt.Sprintf("STOP_RECURRING_%d", recurring.ID),
+		})
+	}
+
+	chunks := utils.SplitMessage(message, utils.MessengerTextLimit)
+	for i, chunk := range chunks {
+		if len(quickReplies) > 0 && i == len(chunks)-1 {
+			err = utils.SendQuickReplies(chunk, quickReplies, psid, token)
+		} else {
+			err = utils.SendTextMessage(chunk, psid, token)
+		}
+		if err != nil {
+			fmt.Printf("Error sending recurring expenses to user %s: %v\n", psid, err)
+			return
+		}
+	}
+}
+
+func handleStopRecurring(recurringID string, psid, token string) {
+	recurring, err := api.StopRecurringExpense(psid, recurringID)
+	if err != nil {
+		fmt.Printf("Error stopping recurring expense %s for user %s: %v\n", recurringID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't stop that recurring expense.", psid, token)
+		return
+	}
+	utils.SendTextMessage(fmt.Sprintf("Stopped. I won't log %s automatically anymore; expenses already logged are kept.", recurring.Category), psid, token)
+}
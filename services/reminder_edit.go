@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// reminderEdit is the reminder and field a user is currently changing.
+type reminderEdit struct {
+	ReminderID string
+	Field      string // AMOUNT, RECIPIENT, NUMBER, DATE or RESCHEDULE
+}
+
+// pendingReminderEdits keeps each user's edit in progress until they send the new value.
+// Webhook events are handled concurrently, so access goes through pendingReminderEditsMu.
+var (
+	pendingReminderEdits   = make(map[string]reminderEdit)
+	pendingReminderEditsMu sync.Mutex
+)
+
+var reminderEditPrompts = map[string]string{
+	"AMOUNT":     "Please send the new amount (e.g. 1500.00).",
+	"RECIPIENT":  "Please send the new recipient name.",
+	"NUMBER":     "Please send the new account number (e.g. 09171234567 for GCash or Maya, or your bank account number).",
+	"DATE":       "Please send the new due date in this format: [month/day/year] at [time]\n(e.g. 05/01/2025 at 9am). The time is optional. For recurring reminders, later payments follow the new date.",
+	"RESCHEDULE": "Please send the date to move this payment to: [month/day/year] at [time]\n(e.g. 05/03/2025 at 9am). The time is optional. Later payments keep their usual schedule.",
+}
+
+// handleManageReminder offers the actions that don't fit on a reminder card.
+func handleManageReminder(reminderID string, psid, token string) {
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Edit", Payload: "EDIT_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "Reschedule", Payload: "RESCHEDULE_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "Skip this payment", Payload: "SKIP_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "Cancel reminder", Payload: "CANCEL_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "Delete", Payload: "DELETE_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "History", Payload: "VIEW_REMINDER_HISTORY_" + reminderID},
+	}
+	if err := utils.SendQuickReplies("What would you like to do with this reminder?", quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending reminder options for user %s: %v\n", psid, err)
+	}
+}
+
+// handleEditReminder asks which detail of a reminder to change.
+func handleEditReminder(reminderID string, psid, token string) {
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Amount", Payload: fmt.Sprintf("REMINDER_FIELD_AMOUNT_%s", reminderID)},
+		{ContentType: "text", Title: "Recipient", Payload: fmt.Sprintf("REMINDER_FIELD_RECIPIENT_%s", reminderID)},
+		{ContentType: "text", Title: "Account number", Payload: fmt.Sprintf("REMINDER_FIELD_NUMBER_%s", reminderID)},
+		{ContentType: "text", Title: "Due date", Payload: fmt.Sprintf("REMINDER_FIELD_DATE_%s", reminderID)},
+	}
+	if err := utils.SendQuickReplies("What would you like to change?", quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending reminder edit options for user %s: %v\n", psid, err)
+	}
+}
+
+// promptReminderEdit starts waiting for the new value of a reminder field. Payload format: <FIELD>_<reminderID>
+func promptReminderEdit(payload string, psid, token string) {
+	parts := strings.SplitN(payload, "_", 2)
+	if len(parts) != 2 {
+		fmt.Printf("Malformed reminder edit payload: %s\n", payload)
+		return
+	}
+	prompt, ok := reminderEditPrompts[parts[0]]
+	if !ok {
+		fmt.Printf("Unknown reminder field in payload %s\n", payload)
+		return
+	}
+
+	pendingReminderEditsMu.Lock()
+	pendingReminderEdits[psid] = reminderEdit{ReminderID: parts[1], Field: parts[0]}
+	pendingReminderEditsMu.Unlock()
+	setUserState(psid, "EDITING_REMINDER")
+	utils.SendTextMessage(prompt, psid, token)
+}
+
+// handleReminderEditInput applies the value sent for the field chosen in promptReminderEdit.
+func handleReminderEditInput(message, psid, token string) {
+	pendingReminderEditsMu.Lock()
+	edit, exists := pendingReminderEdits[psid]
+	delete(pendingReminderEdits, psid)
+	pendingReminderEditsMu.Unlock()
+	setUserState(psid, "WAITING...")
+	if !exists {
+		SendMainMenu(psid, token)
+		return
+	}
+
+	var update api.ReminderUpdate
+	value := strings.TrimSpace(message)
+	switch edit.Field {
+	case "AMOUNT":
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimPrefix(value, "₱"), ",", ""), 64)
+		if err != nil || amount <= 0 {
+			utils.SendTextMessage("That doesn't look like a valid amount. Your reminder was not changed.", psid, token)
+			return
+		}
+		update.Amount = &amount
+	case "RECIPIENT":
+		if value == "" {
+			utils.SendTextMessage("The recipient can't be empty. Your reminder was not changed.", psid, token)
+			return
+		}
+		update.Recipient = &value
+	case "NUMBER":
+		update.AccountNumber = &value
+	case "DATE", "RESCHEDULE":
+		dueDate, hasTime, err := utils.ParseReminderDueDate(value)
+		if err != nil {
+			utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Your reminder was not changed.", err), psid, token)
+			return
+		}
+		if edit.Field == "RESCHEDULE" {
+			reminder, err := api.RescheduleReminder(psid, edit.ReminderID, dueDate, hasTime)
+			if err != nil {
+				fmt.Printf("Error rescheduling reminder %s for user %s: %v\n", edit.ReminderID, psid, err)
+				utils.SendTextMessage("Sorry, I couldn't reschedule that reminder.", psid, token)
+				return
+			}
+			utils.SendTextMessage(fmt.Sprintf("Rescheduled! Payment to %s is now due %s.", reminder.Recipient, utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet)), psid, token)
+			return
+		}
+		update.DueDate = &dueDate
+		update.DueTimeSet = hasTime
+	}
+
+	reminder, err := api.UpdateReminder(psid, edit.ReminderID, update)
+	if errors.Is(err, api.ErrInvalidPaymentDetails) {
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Your reminder was not changed.", err), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error updating reminder %s for user %s: %v\n", edit.ReminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't update that reminder.", psid, token)
+		return
+	}
+
+	message_ := fmt.Sprintf("Reminder updated: Pay ₱%.2f to %s (%s) on %s",
+		reminder.Amount, reminder.Recipient, utils.DescribePaymentAccount(*reminder), utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+	utils.SendTextMessage(message_, psid, token)
+	fmt.Printf("Reminder %s updated for user %s\n", edit.ReminderID, psid)
+}
+
+// handleDeleteReminder asks for confirmation before a reminder is deleted.
+func handleDeleteReminder(reminderID string, psid, token string) {
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Yes, delete it", Payload: "CONFIRM_DELETE_REMINDER_" + reminderID},
+		{ContentType: "text", Title: "No, keep it", Payload: "KEEP_REMINDER_" + reminderID},
+	}
+	if err := utils.SendQuickReplies("Delete this reminder and its payment history? Expenses already logged are kept.", quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending delete confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+func handleConfirmDeleteReminder(reminderID string, psid, token string) {
+	err := api.DeleteReminder(psid, reminderID)
+	if err != nil {
+		fmt.Printf("Error deleting reminder %s for user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't delete that reminder.", psid, token)
+		return
+	}
+	utils.SendTextMessage("Done! The reminder has been deleted.", psid, token)
+	fmt.Printf("Reminder %s deleted for user %s\n", reminderID, psid)
+}
+
+// handleSkipReminder skips the upcoming payment of a reminder.
+func handleSkipReminder(reminderID string, psid, token string) {
+	reminder, err := api.SkipReminder(psid, reminderID)
+	if errors.Is(err, api.ErrInvalidStatusTransition) {
+		utils.SendTextMessage("That reminder is no longer active, so there's nothing to skip.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error skipping reminder %s for user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't skip that payment.", psid, token)
+		return
+	}
+
+	if reminder.Status == models.ReminderSkipped {
+		utils.SendTextMessage(fmt.Sprintf("Skipped. I won't remind you about the payment to %s anymore.", reminder.Recipient), psid, token)
+		return
+	}
+	utils.SendTextMessage(fmt.Sprintf("Skipped. Your next payment to %s is due %s.", reminder.Recipient, utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet)), psid, token)
+}
+
+// handleCancelReminder stops a reminder while keeping its payment history.
+func handleCancelReminder(reminderID string, psid, token string) {
+	reminder, err := api.CancelReminder(psid, reminderID)
+	if errors.Is(err, api.ErrInvalidStatusTransition) {
+		utils.SendTextMessage("That reminder is no longer active, so it can't be cancelled.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error cancelling reminder %s for user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't cancel that reminder.", psid, token)
+		return
+	}
+	utils.SendTextMessage(fmt.Sprintf("Cancelled. I won't remind you about the payment to %s anymore.", reminder.Recipient), psid, token)
+}
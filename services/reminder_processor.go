@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"quickyexpensetracker/api"
+	"quickyexpensetracker/models"
 	"quickyexpensetracker/templates"
 	"quickyexpensetracker/utils"
 	"time"
@@ -20,6 +21,12 @@ func CheckDueReminders() {
 		return
 	}
 
+	now := time.Now()
+	preferences := make(map[string]*models.UserPreference) // Loaded once per user per run
+
+	processElapsedSnoozes(now, preferences, token)
+	processOverdueReminders(now, preferences, token)
+
 	reminders, err := api.GetPendingUnnotifiedReminders()
 	if err != nil {
 		fmt.Printf("Reminder Processor: Error fetching reminders: %v\n", err)
@@ -33,13 +40,28 @@ func CheckDueReminders() {
 
 	fmt.Printf("Reminder Processor: Found %d pending unnotified reminders.\n", len(reminders))
 
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
 	for _, reminder := range reminders {
-		dueDate := time.Date(reminder.DueDate.Year(), reminder.DueDate.Month(), reminder.DueDate.Day(), 0, 0, 0, 0, reminder.DueDate.Location())
+		if reminder.SnoozedUntil != nil && now.Before(*reminder.SnoozedUntil) {
+			continue // Snoozed by the user; processElapsedSnoozes picks it up once the snooze ends
+		}
+
+		preference, err := loadPreference(preferences, reminder.UserID)
+		if err != nil {
+			fmt.Printf("Reminder Processor: Error fetching preferences for user %s: %v\n", reminder.UserID, err)
+			continue
+		}
+		dueAt := utils.ReminderDueAt(reminder.DueDate, reminder.DueTimeSet, preference.ReminderTime, api.UserLocation(preference))
 
-		if dueDate.Before(today) || dueDate.Equal(today) {
+		if reminder.ReminderType == "payment" && now.Before(dueAt) {
+			leadDays := reminder.LeadDays
+			if leadDays == "" {
+				leadDays = preference.ReminderLeadDays
+			}
+			sendAdvanceNotice(reminder, dueAt, leadDays, now, token)
+			continue
+		}
+
+		if !now.Before(dueAt) {
 			fmt.Printf("Reminder Processor: Processing Reminder ID %d (Type: %s, Frequency: %s) for User %s.\n",
 				reminder.ID, reminder.ReminderType, reminder.Frequency, reminder.UserID)
 
@@ -50,7 +72,7 @@ func CheckDueReminders() {
 			case "payment":
 				// Send plain text message first
 				message := fmt.Sprintf("Hi there! This is a friendly reminder that your payment of ₱%.2f to %s is due today (%s).",
-					reminder.Amount, reminder.Recipient, reminder.DueDate.Format("Jan 2, 2006"))
+					reminder.Amount, reminder.Recipient, formatDueForMessage(reminder))
 
 				err := utils.SendTextMessage(message, reminder.UserID, token)
 				if err != nil {
@@ -59,34 +81,7 @@ func CheckDueReminders() {
 				}
 
 				// Then send the payment template with buttons
-				title := fmt.Sprintf("Payment to %s", reminder.Recipient)
-				subtitle := fmt.Sprintf("Amount: ₱%.2f\nGCash: %s\nDue: %s",
-					reminder.Amount,
-					reminder.GcashNumber,
-					dueDate.Format("2006-01-02"))
-
-				var buttons []templates.Button
-				if reminder.PaymentMethod == "Gcash" && reminder.Status == "pending" {
-					buttons = append(buttons, templates.Button{
-						Type:    "postback",
-						Title:   "Pay with GCash",
-						Payload: "PAY_GCASH_" + fmt.Sprint(reminder.ID),
-					})
-					buttons = append(buttons, templates.Button{
-						Type:    "postback",
-						Title:   "Mark as Paid",
-						Payload: "MARK_AS_PAID_" + fmt.Sprint(reminder.ID),
-					})
-				}
-
-				element := templates.Template{
-					Title:    title,
-					Subtitle: subtitle,
-					Buttons:  buttons,
-				}
-
-				// Send the template
-				err = utils.SendTemplateMessage([]templates.Template{element}, reminder.UserID, token)
+				err = sendPaymentCard(reminder, token)
 				if err != nil {
 					processingError = fmt.Errorf("error sending payment template: %w", err)
 				} else {
@@ -94,6 +89,15 @@ func CheckDueReminders() {
 					fmt.Printf("Reminder Processor: Payment template sent for reminder ID %d.\n", reminder.ID)
 				}
 
+			case "debt":
+				err := sendDebtReminder(reminder, token)
+				if err != nil {
+					processingError = fmt.Errorf("error sending debt reminder: %w", err)
+				} else {
+					notificationSent = true
+					fmt.Printf("Reminder Processor: Debt reminder handled for reminder ID %d.\n", reminder.ID)
+				}
+
 			case "expense_summary":
 				// Determine period for expense summary
 				var periodStartDate, periodEndDate time.Time
@@ -139,9 +143,22 @@ func CheckDueReminders() {
 				continue
 			}
 
-			// Handle reminder status update based on frequency
+			// Handle reminder status update based on its recurrence
 			if notificationSent || reminder.ReminderType == "payment" {
-				if reminder.Frequency == "once" || reminder.Frequency == "" {
+				if reminder.ReminderType == "payment" {
+					if err := api.RecordReminderOccurrence(reminder, now); err != nil {
+						fmt.Printf("Reminder Processor: Error recording occurrence for reminder ID %d: %v\n",
+							reminder.ID, err)
+					}
+				}
+
+				rule, recurring, err := utils.ReminderRule(reminder.Frequency, reminder.RRule)
+				if err != nil {
+					fmt.Printf("Reminder Processor: Invalid recurrence for reminder ID %d: %v. Marking as notified.\n",
+						reminder.ID, err)
+				}
+
+				if !recurring {
 					err = api.MarkReminderAsNotified(fmt.Sprint(reminder.ID))
 					if err != nil {
 						fmt.Printf("Reminder Processor: Error marking reminder ID %d as notified: %v\n",
@@ -149,36 +166,215 @@ func CheckDueReminders() {
 					} else {
 						fmt.Printf("Reminder Processor: Reminder ID %d (once) marked as notified.\n", reminder.ID)
 					}
-				} else if reminder.Frequency == "daily" || reminder.Frequency == "weekly" || reminder.Frequency == "monthly" {
-					nextDueDate, err := utils.CalculateNextDueDate(reminder.DueDate, reminder.Frequency)
-					if err != nil {
-						fmt.Printf("Reminder Processor: Error calculating next due date for reminder ID %d: %v. Marking as notified.\n",
-							reminder.ID, err)
-						if markErr := api.MarkReminderAsNotified(fmt.Sprint(reminder.ID)); markErr != nil {
-							fmt.Printf("Reminder Processor: Error marking reminder ID %d as notified after calc error: %v\n",
-								reminder.ID, markErr)
-						}
-						continue
-					}
+					continue
+				}
 
-					err = api.UpdateReminderDueDateAndNotifiedStatus(fmt.Sprint(reminder.ID), nextDueDate, false)
-					if err != nil {
-						fmt.Printf("Reminder Processor: Error updating reminder ID %d for next occurrence: %v\n",
+				seriesStart := reminder.DueDate
+				if reminder.RecurrenceStart != nil {
+					seriesStart = *reminder.RecurrenceStart
+				}
+
+				nextDueDate, ok := rule.Next(seriesStart, reminder.DueDate)
+				if !ok {
+					// The series has ended; treat the last occurrence like a one-time reminder.
+					if err := api.MarkReminderAsNotified(fmt.Sprint(reminder.ID)); err != nil {
+						fmt.Printf("Reminder Processor: Error marking ended reminder ID %d as notified: %v\n",
 							reminder.ID, err)
 					} else {
-						fmt.Printf("Reminder Processor: Reminder ID %d rescheduled to %s. Notified status reset.\n",
-							reminder.ID, nextDueDate.Format("Jan 2, 2006"))
+						fmt.Printf("Reminder Processor: Reminder ID %d reached the end of its schedule.\n", reminder.ID)
 					}
+					continue
+				}
+
+				err = api.UpdateReminderDueDateAndNotifiedStatus(fmt.Sprint(reminder.ID), nextDueDate, false)
+				if err != nil {
+					fmt.Printf("Reminder Processor: Error updating reminder ID %d for next occurrence: %v\n",
+						reminder.ID, err)
 				} else {
-					fmt.Printf("Reminder Processor: Unknown frequency '%s' for reminder ID %d. Marking as notified.\n",
-						reminder.Frequency, reminder.ID)
-					if err := api.MarkReminderAsNotified(fmt.Sprint(reminder.ID)); err != nil {
-						fmt.Printf("Reminder Processor: Error marking reminder ID %d with unknown frequency as notified: %v\n",
-							reminder.ID, err)
-					}
+					fmt.Printf("Reminder Processor: Reminder ID %d rescheduled to %s. Notified status reset.\n",
+						reminder.ID, nextDueDate.Format("Jan 2, 2006"))
 				}
 			}
 		}
 	}
 	fmt.Println("Reminder Processor: Finished checking due reminders.")
 }
+
+// sendPaymentCard sends the payment template for a reminder with its payment buttons.
+func sendPaymentCard(reminder models.RemindersLog, token string) error {
+	title := fmt.Sprintf("Payment to %s", reminder.Recipient)
+	subtitle := fmt.Sprintf("Amount: ₱%.2f\n%s\nDue: %s",
+		reminder.Amount,
+		utils.DescribePaymentAccount(reminder),
+		utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+
+	var buttons []templates.Button
+	if api.IsActiveReminderStatus(reminder.Status) {
+		if payButton, ok := utils.PayButton(reminder); ok {
+			buttons = append(buttons, payButton)
+		}
+		buttons = append(buttons, templates.Button{
+			Type:    "postback",
+			Title:   "Mark as Paid",
+			Payload: "MARK_AS_PAID_" + fmt.Sprint(reminder.ID),
+		})
+		buttons = append(buttons, templates.Button{
+			Type:    "postback",
+			Title:   "Snooze",
+			Payload: "SNOOZE_" + fmt.Sprint(reminder.ID),
+		})
+	}
+
+	element := templates.Template{
+		Title:    title,
+		Subtitle: subtitle,
+		Buttons:  buttons,
+	}
+
+	return utils.SendTemplateMessage([]templates.Template{element}, reminder.UserID, token)
+}
+
+// sendAdvanceNotice sends the lead-time notice for a payment reminder that is not yet due,
+// if one of its stages has fired and has not already been sent for this due date.
+func sendAdvanceNotice(reminder models.RemindersLog, dueAt time.Time, leadDays string, now time.Time, token string) {
+	stages, err := utils.ParseLeadDays(leadDays)
+	if err != nil {
+		fmt.Printf("Reminder Processor: Invalid lead days '%s' for reminder ID %d: %v\n", leadDays, reminder.ID, err)
+		return
+	}
+
+	stage, ok := utils.LeadStageAt(dueAt, now, stages)
+	if !ok {
+		return
+	}
+
+	sent, err := api.HasSentReminderStage(reminder.ID, reminder.DueDate, stage)
+	if err != nil {
+		fmt.Printf("Reminder Processor: Error checking notice stage for reminder ID %d: %v\n", reminder.ID, err)
+		return
+	}
+	if sent {
+		return
+	}
+
+	message := fmt.Sprintf("Heads up! Your payment of ₱%.2f to %s is due in %d day(s) (%s).",
+		reminder.Amount, reminder.Recipient, utils.DaysUntil(dueAt, now), formatDueForMessage(reminder))
+	if err := utils.SendTextMessage(message, reminder.UserID, token); err != nil {
+		fmt.Printf("Reminder Processor: Error sending advance notice for reminder ID %d: %v\n", reminder.ID, err)
+		return
+	}
+	if err := sendPaymentCard(reminder, token); err != nil {
+		fmt.Printf("Reminder Processor: Error sending payment template for reminder ID %d: %v\n", reminder.ID, err)
+	}
+
+	if err := api.RecordReminderStage(reminder.ID, reminder.DueDate, stage); err != nil {
+		fmt.Printf("Reminder Processor: Error recording notice stage for reminder ID %d: %v\n", reminder.ID, err)
+		return
+	}
+	fmt.Printf("Reminder Processor: %d-day notice sent for reminder ID %d.\n", stage, reminder.ID)
+}
+
+// formatDueForMessage renders a reminder's due date for notification text.
+func formatDueForMessage(reminder models.RemindersLog) string {
+	if reminder.DueTimeSet {
+		return reminder.DueDate.Format("Jan 2, 2006 at 3:04 PM")
+	}
+	return reminder.DueDate.Format("Jan 2, 2006")
+}
+
+// loadPreference returns a user's preferences, fetching them at most once per processor run.
+func loadPreference(preferences map[string]*models.UserPreference, userID string) (*models.UserPreference, error) {
+	if preference, loaded := preferences[userID]; loaded {
+		return preference, nil
+	}
+
+	preference, err := api.GetUserPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences[userID] = preference
+	return preference, nil
+}
+
+// processElapsedSnoozes re-sends reminders whose snooze has run out. A reminder that is now due
+// and not yet notified only has its snooze cleared, since the regular due notification covers it.
+func processElapsedSnoozes(now time.Time, preferences map[string]*models.UserPreference, token string) {
+	reminders, err := api.GetElapsedSnoozedReminders(now)
+	if err != nil {
+		fmt.Printf("Reminder Processor: Error fetching snoozed reminders: %v\n", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		preference, err := loadPreference(preferences, reminder.UserID)
+		if err != nil {
+			fmt.Printf("Reminder Processor: Error fetching preferences for user %s: %v\n", reminder.UserID, err)
+			continue
+		}
+		dueAt := utils.ReminderDueAt(reminder.DueDate, reminder.DueTimeSet, preference.ReminderTime, api.UserLocation(preference))
+
+		if reminder.Notified || now.Before(dueAt) {
+			message := fmt.Sprintf("⏰ Snoozed reminder: don't forget your payment of ₱%.2f to %s.", reminder.Amount, reminder.Recipient)
+			if err := utils.SendTextMessage(message, reminder.UserID, token); err != nil {
+				fmt.Printf("Reminder Processor: Error sending snoozed reminder ID %d: %v\n", reminder.ID, err)
+				continue
+			}
+			if err := sendPaymentCard(reminder, token); err != nil {
+				fmt.Printf("Reminder Processor: Error sending payment template for reminder ID %d: %v\n", reminder.ID, err)
+			}
+		}
+
+		if err := api.ClearReminderSnooze(reminder.ID); err != nil {
+			fmt.Printf("Reminder Processor: Error clearing snooze for reminder ID %d: %v\n", reminder.ID, err)
+		} else {
+			fmt.Printf("Reminder Processor: Snooze ended for reminder ID %d.\n", reminder.ID)
+		}
+	}
+}
+
+// processOverdueReminders nudges users about one-time payments that are past due and unpaid,
+// following the escalation schedule in utils.NextEscalationAt.
+func processOverdueReminders(now time.Time, preferences map[string]*models.UserPreference, token string) {
+	reminders, err := api.GetEscalatableReminders()
+	if err != nil {
+		fmt.Printf("Reminder Processor: Error fetching overdue reminders: %v\n", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		if reminder.SnoozedUntil != nil && now.Before(*reminder.SnoozedUntil) {
+			continue
+		}
+
+		preference, err := loadPreference(preferences, reminder.UserID)
+		if err != nil {
+			fmt.Printf("Reminder Processor: Error fetching preferences for user %s: %v\n", reminder.UserID, err)
+			continue
+		}
+		dueAt := utils.ReminderDueAt(reminder.DueDate, reminder.DueTimeSet, preference.ReminderTime, api.UserLocation(preference))
+
+		if now.Before(utils.NextEscalationAt(dueAt, reminder.EscalationCount)) {
+			continue
+		}
+
+		daysOverdue := -utils.DaysUntil(dueAt, now)
+		message := fmt.Sprintf("⚠️ Your payment of ₱%.2f to %s is %d day(s) overdue (due %s). Please settle it as soon as you can.",
+			reminder.Amount, reminder.Recipient, daysOverdue, formatDueForMessage(reminder))
+		if err := utils.SendTextMessage(message, reminder.UserID, token); err != nil {
+			fmt.Printf("Reminder Processor: Error sending overdue notice for reminder ID %d: %v\n", reminder.ID, err)
+			continue
+		}
+
+		reminder.Status = models.ReminderOverdue
+		if err := sendPaymentCard(reminder, token); err != nil {
+			fmt.Printf("Reminder Processor: Error sending payment template for reminder ID %d: %v\n", reminder.ID, err)
+		}
+
+		if err := api.RecordReminderEscalation(reminder.ID, now); err != nil {
+			fmt.Printf("Reminder Processor: Error recording escalation for reminder ID %d: %v\n", reminder.ID, err)
+		} else {
+			fmt.Printf("Reminder Processor: Overdue notice %d sent for reminder ID %d (%d days overdue).\n",
+				reminder.EscalationCount+1, reminder.ID, daysOverdue)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strings"
+	"time"
+)
+
+func handleDefaultLeadDays(message, psid, token string) {
+	leadDays, err := utils.GetDefaultLeadDaysFromMessage(message)
+	if err == nil {
+		err = api.UpdateReminderLeadDays(psid, leadDays)
+	}
+	if err != nil {
+		fmt.Printf("Error updating default lead days for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't update your notice settings. Please use the format: notify me [days] days before (e.g. notify me 3,1 days before)", psid, token)
+		return
+	}
+
+	days, _ := utils.ParseLeadDays(leadDays)
+	utils.SendTextMessage(fmt.Sprintf("Got it! By default I'll notify you about payments %s.", utils.FormatLeadDays(days)), psid, token)
+}
+
+func handleDefaultReminderTime(message, psid, token string) {
+	reminderTime, err := utils.GetReminderTimeFromMessage(message)
+	if err == nil {
+		err = api.UpdateReminderTime(psid, reminderTime)
+	}
+	if err != nil {
+		fmt.Printf("Error updating default reminder time for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't update your reminder time. Please use the format: remind me at [time] (e.g. remind me at 8am)", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Got it! Reminders without a set time will now arrive at %s.", reminderTime), psid, token)
+}
+
+func handleTimezone(message, psid, token string) {
+	timezone, err := utils.GetTimezoneFromMessage(message)
+	if err == nil {
+		err = api.UpdateTimezone(psid, timezone)
+	}
+	if err != nil {
+		fmt.Printf("Error updating timezone for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I don't recognize that timezone. Please use a name like: timezone Asia/Manila", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Got it! Your reminders will follow the %s timezone.", timezone), psid, token)
+}
+
+// snoozeOptions maps the snooze quick replies to their labels.
+var snoozeOptions = map[string]string{
+	"1H":       "1 hour",
+	"TOMORROW": "tomorrow",
+	"3D":       "3 days",
+}
+
+// handleSnoozeCommand handles the body of a SNOOZE_ payload: either "<id>", which offers the
+// snooze options, or "<option>_<id>", which snoozes the reminder.
+func handleSnoozeCommand(body, psid, token string) {
+	parts := strings.SplitN(body, "_", 2)
+	if len(parts) == 1 {
+		reminderID := parts[0]
+		quickReplies := []templates.QuickReply{
+			{ContentType: "text", Title: "1 hour", Payload: "SNOOZE_1H_" + reminderID},
+			{ContentType: "text", Title: "Tomorrow", Payload: "SNOOZE_TOMORROW_" + reminderID},
+			{ContentType: "text", Title: "3 days", Payload: "SNOOZE_3D_" + reminderID},
+		}
+		utils.SendQuickReplies("When should I remind you again?", quickReplies, psid, token)
+		return
+	}
+
+	option, reminderID := parts[0], parts[1]
+	label, ok := snoozeOptions[option]
+	if !ok {
+		fmt.Printf("Unknown snooze option %s for user %s\n", option, psid)
+		return
+	}
+
+	preference, err := api.GetUserPreference(psid)
+	if err != nil {
+		fmt.Printf("Error fetching preferences for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't snooze that reminder.", psid, token)
+		return
+	}
+
+	now := time.Now()
+	var until time.Time
+	switch option {
+	case "1H":
+		until = now.Add(time.Hour)
+	case "TOMORROW":
+		// Tomorrow at the user's usual reminder time
+		tomorrow := now.In(api.UserLocation(preference)).AddDate(0, 0, 1)
+		until = utils.ReminderDueAt(tomorrow, false, preference.ReminderTime, api.UserLocation(preference))
+	case "3D":
+		until = now.AddDate(0, 0, 3)
+	}
+
+	reminder, err := api.SnoozeReminder(psid, reminderID, label, until)
+	if errors.Is(err, api.ErrSnoozeLimitReached) {
+		utils.SendTextMessage(fmt.Sprintf("This reminder has already been snoozed %d times. Please pay or mark it as paid.", api.MaxReminderSnoozes), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error snoozing reminder %s for user %s: %v\n", reminderID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't snooze that reminder.", psid, token)
+		return
+	}
+
+	remaining := api.MaxReminderSnoozes - reminder.SnoozeCount
+	message := fmt.Sprintf("Okay, I'll remind you again %s (%s). The due date stays %s. Snoozes left: %d.",
+		label, until.In(api.UserLocation(preference)).Format("Jan 2 at 3:04 PM"), utils.FormatDueDate(reminder.DueDate, reminder.DueTimeSet), remaining)
+	utils.SendTextMessage(message, psid, token)
+}
+
+// sendReminderSaved confirms a new payment reminder to the user.
+func sendReminderSaved(details utils.ReminderDetails, rule *utils.RecurrenceRule, leadDays string, psid, token string) {
+	paymentAccount := utils.DescribePaymentDetails(details.Payment)
+	message := fmt.Sprintf("Reminder: Pay ₱%.2f to %s (%s) on %s", details.Amount, details.Recipient, paymentAccount, details.DueDate.Format("01/02/2006"))
+	if details.HasTime {
+		message += details.DueDate.Format(" at 3:04 PM")
+	}
+	if rule != nil {
+		message += fmt.Sprintf("\n%s.", rule.Describe())
+	}
+	if leadDays != "" {
+		days, _ := utils.ParseLeadDays(leadDays)
+		message += fmt.Sprintf("\nI'll notify you %s.", utils.FormatLeadDays(days))
+	}
+	utils.SendTextMessage(message, psid, token)
+	fmt.Printf("Reminder saved for user %s: ₱%.2f to %s (%s) on %s\n", psid, details.Amount, details.Recipient, paymentAccount, details.DueDate.Format("01/02/2006"))
+}
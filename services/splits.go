@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"time"
+)
+
+// handleSplitExpense logs an expense shared with others, e.g. "1200 for lunch split with ana, ben".
+func handleSplitExpense(message, psid, token string) {
+	amount, category, people, err := utils.GetSplitExpenseDataFromMessage(message)
+	if err != nil {
+		fmt.Printf("Error parsing split expense for user %s: %v\n", psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, %v. Please use the format: [amount] for [item] split with [name], [name]\n(e.g. 1200 for lunch split with ana, ben or 1200 for lunch split with ana 500, ben 300)", err), psid, token)
+		return
+	}
+
+	payerShare, shares, err := utils.ComputeSplitShares(amount, people)
+	if err != nil {
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't split that: %v.", err), psid, token)
+		return
+	}
+
+	split, _, err := api.SaveSplitExpense(psid, category, amount, payerShare, shares)
+	if err != nil {
+		fmt.Printf("Error saving split expense for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't save your expense. Please try again later.", psid, token)
+		return
+	}
+
+	reply := fmt.Sprintf("Got it! You spent ₱%.2f on %s, your share of ₱%.2f.", payerShare, category, amount)
+	for _, share := range shares {
+		reply += fmt.Sprintf("\n• %s owes you ₱%.2f", share.Name, share.Amount)
+	}
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "Who owes me", Payload: "WHO_OWES_ME"},
+		{ContentType: "text", Title: "Undo split", Payload: fmt.Sprintf("UNDO_SPLIT_%d", split.ID)},
+	}
+	if err := utils.SendQuickReplies(reply, quickReplies, psid, token); err != nil {
+		fmt.Printf("Error sending split confirmation for user %s: %v\n", psid, err)
+	}
+}
+
+func handleUndoSplit(splitID string, psid, token string) {
+	if err := api.UndoSplitExpense(psid, splitID); err != nil {
+		fmt.Printf("Error undoing split %s for user %s: %v\n", splitID, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't undo that split.", psid, token)
+		return
+	}
+	utils.SendTextMessage("Done! That split expense and what others owed for it have been removed.", psid, token)
+}
+
+func sendOwedBalances(psid, token string) {
+	debts, err := api.GetDebtBalances(psid)
+	if err != nil {
+		fmt.Printf("Error fetching owed balances for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't work out who owes you at the moment. Please try again later.", psid, token)
+		return
+	}
+	for _, chunk := range utils.SplitMessage(utils.GetOwedBalancesReport(debts), utils.MessengerTextLimit) {
+		utils.SendTextMessage(chunk, psid, token)
+	}
+}
+
+// handleSettle records someone paying back what they owe, e.g. "settle ana 200" or "settle ana".
+// It's the same as "ana paid back 200", and covers loans as well as split expenses.
+func handleSettle(message, psid, token string) {
+	name, amount, err := utils.GetSettleDataFromMessage(message)
+	if err != nil {
+		utils.SendTextMessage("Please use the format: settle [name] [amount]\n(e.g. settle ana 200, or settle ana to clear everything)", psid, token)
+		return
+	}
+
+	var debt *utils.PersonDebt
+	if amount == nil {
+		debt, err = api.GetDebtBalance(psid, name)
+		if err == nil && debt.Balance < 0.005 {
+			err = api.ErrNoDebt
+		}
+		if err == nil {
+			outstanding := math.Round(debt.Balance*100) / 100
+			amount = &outstanding
+		}
+	}
+	if err == nil {
+		debt, err = api.RecordRepayment(psid, name, *amount, true, time.Now())
+	}
+	if errors.Is(err, api.ErrNoDebt) {
+		utils.SendTextMessage(fmt.Sprintf("%s doesn't owe you anything right now.", name), psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error settling balance with %s for user %s: %v\n", name, psid, err)
+		utils.SendTextMessage(fmt.Sprintf("Sorry, I couldn't record that: %v.", err), psid, token)
+		return
+	}
+
+	if debt.Balance < 0.005 {
+		utils.SendTextMessage(fmt.Sprintf("All settled! %s doesn't owe you anything now.", debt.Name), psid, token)
+		return
+	}
+	utils.SendTextMessage(fmt.Sprintf("Recorded. %s still owes you ₱%.2f.", debt.Name, debt.Balance), psid, token)
+}
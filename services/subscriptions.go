@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"quickyexpensetracker/api"
+	"quickyexpensetracker/billing"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"quickyexpensetracker/utils"
+	"strings"
+	"time"
+)
+
+// renewalReminderWindow is how long before a trial or paid period ends the user is reminded.
+const renewalReminderWindow = 3 * 24 * time.Hour
+
+// CheckSubscriptions expires subscriptions whose period has ended and reminds users whose
+// trial or plan is about to end, with a button to renew.
+func CheckSubscriptions() {
+	token := os.Getenv("PAGE_TOKEN")
+	if token == "" {
+		fmt.Println("Subscriptions: Error - PAGE_TOKEN not set. Cannot send messages.")
+		return
+	}
+
+	now := time.Now()
+	expired, err := api.ExpireSubscriptions(now)
+	if err != nil {
+		fmt.Printf("Subscriptions: Error expiring subscriptions: %v\n", err)
+	}
+	for _, subscription := range expired {
+		fmt.Printf("Subscriptions: Subscription ID %d for user %s expired.\n", subscription.ID, subscription.UserID)
+		if subscription.CancelAtPeriodEnd {
+			continue
+		}
+		message := "Your Premium plan has ended, so CSV exports are locked. Renew anytime to get them back."
+		if subscription.Status == models.SubscriptionTrialing {
+			message = "Your free trial of Premium has ended. Subscribe to keep exporting your expenses as CSV."
+		}
+		sendRenewalPrompt(message, subscription, token)
+	}
+
+	due, err := api.GetSubscriptionsDueForRenewalReminder(now, renewalReminderWindow)
+	if err != nil {
+		fmt.Printf("Subscriptions: Error fetching subscriptions due for renewal: %v\n", err)
+		return
+	}
+	for i := range due {
+		subscription := &due[i]
+		ends := utils.FormatDueDate(subscription.CurrentPeriodEnd, false)
+		message := fmt.Sprintf("Heads up: your Premium plan ends on %s. Renew now to keep CSV exports.", ends)
+		if subscription.Status == models.SubscriptionTrialing {
+			message = fmt.Sprintf("Heads up: your free trial of Premium ends on %s. Subscribe to keep CSV exports.", ends)
+		}
+		if !sendRenewalPrompt(message, *subscription, token) {
+			continue
+		}
+		if err := api.MarkRenewalReminderSent(subscription, now); err != nil {
+			fmt.Printf("Subscriptions: Error marking renewal reminder sent for subscription ID %d: %v\n", subscription.ID, err)
+		}
+	}
+}
+
+// sendRenewalPrompt sends a message with a button to pay for another period of the user's plan.
+func sendRenewalPrompt(message string, subscription models.Subscription, token string) bool {
+	title := "Renew"
+	if subscription.Status == models.SubscriptionTrialing {
+		title = "Subscribe"
+	}
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: title, Payload: "SUBSCRIBE_" + strings.ToUpper(subscription.PlanKey)},
+		{ContentType: "text", Title: "See plans", Payload: "SUBSCRIPTION_STATUS"},
+	}
+	if err := utils.SendQuickReplies(message, quickReplies, subscription.UserID, token); err != nil {
+		fmt.Printf("Subscriptions: Error notifying user %s: %v\n", subscription.UserID, err)
+		return false
+	}
+	return true
+}
+
+// sendSubscriptionStatus shows the user's current plan followed by a card per plan to buy.
+func sendSubscriptionStatus(psid, token string) {
+	subscription, err := api.GetSubscription(psid)
+	if errors.Is(err, api.ErrNoSubscription) {
+		subscription = nil
+	} else if err != nil {
+		fmt.Printf("Error fetching subscription for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't fetch your subscription at the moment. Please try again later.", psid, token)
+		return
+	}
+	live := billing.IsLive(subscription, time.Now())
+
+	message := utils.GetSubscriptionStatusMessage(subscription, live)
+	if live && !subscription.CancelAtPeriodEnd {
+		quickReplies := []templates.QuickReply{
+			{ContentType: "text", Title: "Cancel plan", Payload: "CANCEL_SUBSCRIPTION"},
+		}
+		err = utils.SendQuickReplies(message, quickReplies, psid, token)
+	} else {
+		err = utils.SendTextMessage(message, psid, token)
+	}
+	if err != nil {
+		fmt.Printf("Error sending subscription status to user %s: %v\n", psid, err)
+		return
+	}
+
+	var cards []templates.Template
+	for _, plan := range billing.Plans {
+		card := templates.Template{
+			Title:    fmt.Sprintf("%s - ₱%.2f/%s", plan.Name, plan.Price, plan.PeriodLabel()),
+			Subtitle: "Export your expenses as a CSV file.",
+			Buttons: []templates.Button{
+				{Type: "postback", Title: "Subscribe", Payload: "SUBSCRIBE_" + strings.ToUpper(plan.Key)},
+			},
+		}
+		if live && strings.EqualFold(subscription.PlanKey, plan.Key) {
+			card.Buttons[0].Title = "Renew"
+		}
+		// One free trial per user
+		if subscription == nil && plan.TrialDays > 0 {
+			card.Subtitle += fmt.Sprintf(" Try it free for %d days.", plan.TrialDays)
+			card.Buttons = append(card.Buttons, templates.Button{
+				Type: "postback", Title: "Start Free Trial", Payload: "START_TRIAL_" + strings.ToUpper(plan.Key),
+			})
+		}
+		cards = append(cards, card)
+	}
+	if err := utils.SendTemplateMessage(cards, psid, token); err != nil {
+		fmt.Printf("Error sending plans to user %s: %v\n", psid, err)
+	}
+}
+
+func handleStartTrial(planKey string, psid, token string) {
+	subscription, err := api.StartTrial(psid, planKey, time.Now())
+	if errors.Is(err, api.ErrTrialUsed) {
+		utils.SendTextMessage("You've already used your free trial. Subscribe to a plan to get Premium.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error starting trial of %s for user %s: %v\n", planKey, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't start your free trial. Please try again later.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Your free trial has started! You have Premium until %s. Try typing \"export\" to download this month's expenses.",
+		utils.FormatDueDate(subscription.CurrentPeriodEnd, false)), psid, token)
+}
+
+func handleSubscribe(planKey string, psid, token string) {
+	subscription, payment, err := api.Subscribe(psid, planKey, time.Now())
+	if errors.Is(err, billing.ErrPaymentDeclined) {
+		utils.SendTextMessage("Your payment was declined, so nothing was charged. Please try again or use another payment method.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error subscribing user %s to %s: %v\n", psid, planKey, err)
+		utils.SendTextMessage("Sorry, I couldn't complete your subscription. Please try again later.", psid, token)
+		return
+	}
+
+	planName := subscription.PlanKey
+	if plan, ok := billing.GetPlan(subscription.PlanKey); ok {
+		planName = plan.Name
+	}
+	utils.SendTextMessage(fmt.Sprintf("Thank you! You paid ₱%.2f for %s, which is active until %s.\nReference: %s",
+		payment.Amount, planName, utils.FormatDueDate(subscription.CurrentPeriodEnd, false), payment.ProviderReference), psid, token)
+}
+
+func handleCancelSubscription(psid, token string) {
+	subscription, err := api.CancelSubscription(psid)
+	if errors.Is(err, api.ErrNoSubscription) {
+		utils.SendTextMessage("You don't have an active Premium plan to cancel.", psid, token)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error cancelling subscription for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't cancel your plan. Please try again later.", psid, token)
+		return
+	}
+
+	utils.SendTextMessage(fmt.Sprintf("Your plan is cancelled. You keep Premium until %s and won't be reminded to renew.",
+		utils.FormatDueDate(subscription.CurrentPeriodEnd, false)), psid, token)
+}
+
+// requireEntitlement reports whether the user's plan includes the feature. When it doesn't,
+// the user is told it's a Premium feature and offered the plans.
+func requireEntitlement(feature billing.Feature, psid, token string) bool {
+	entitled, err := api.HasEntitlement(psid, feature)
+	if err != nil {
+		fmt.Printf("Error checking %s entitlement for user %s: %v\n", feature, psid, err)
+		utils.SendTextMessage("Sorry, I couldn't check your plan at the moment. Please try again later.", psid, token)
+		return false
+	}
+	if entitled {
+		return true
+	}
+
+	quickReplies := []templates.QuickReply{
+		{ContentType: "text", Title: "See plans", Payload: "SUBSCRIPTION_STATUS"},
+	}
+	utils.SendQuickReplies(fmt.Sprintf("%s is a Premium feature. Subscribe or start a free trial to unlock it.", featureName(feature)), quickReplies, psid, token)
+	return false
+}
+
+func featureName(feature billing.Feature) string {
+	switch feature {
+	case billing.FeatureExports:
+		return "Exporting expenses"
+	default:
+		return "This"
+	}
+}
+
+// sendExpenseExport sends the user's expenses for the range as a CSV file. Premium only.
+func sendExpenseExport(rangeType string, psid, token string) {
+	if !requireEntitlement(billing.FeatureExports, psid, token) {
+		return
+	}
+
+	expenses, err := api.GetExpensesByUserAndRange(psid, rangeType)
+	if err != nil {
+		fmt.Printf("Error fetching expenses to export for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't export your expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+	if len(expenses) == 0 {
+		utils.SendTextMessage(fmt.Sprintf("You have no expenses in the last %s to export.", rangeType), psid, token)
+		return
+	}
+
+	preference, err := api.GetUserPreference(psid)
+	if err != nil {
+		fmt.Printf("Error fetching preferences for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't export your expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+	loc := api.UserLocation(preference)
+
+	data, err := utils.BuildExpensesCSV(expenses, loc)
+	if err != nil {
+		fmt.Printf("Error building export for user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't export your expenses at the moment. Please try again later.", psid, token)
+		return
+	}
+
+	filename := fmt.Sprintf("expenses-%s-%s.csv", rangeType, time.Now().In(loc).Format("2006-01-02"))
+	if err := utils.SendFileAttachment(data, filename, "text/csv", psid, token); err != nil {
+		fmt.Printf("Error sending export to user %s: %v\n", psid, err)
+		utils.SendTextMessage("Sorry, I couldn't send your export. Please try again later.", psid, token)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"quickyexpensetracker/utils"
+)
+
+// ProcessTextCommand handles typed commands that work from anywhere in the conversation,
+// regardless of the user's current state. It reports whether the message was handled.
+func ProcessTextCommand(message, psid, mid, token string) bool {
+	switch {
+	case utils.IsGoalFormatCorrect(message):
+		handleNewGoal(message, psid, token)
+	case utils.IsGoalContributionFormatCorrect(message):
+		handleGoalContribution(message, psid, token)
+	case utils.IsDefaultLeadDaysFormatCorrect(message):
+		handleDefaultLeadDays(message, psid, token)
+	case utils.IsReminderTimeFormatCorrect(message):
+		handleDefaultReminderTime(message, psid, token)
+	case utils.IsTimezoneFormatCorrect(message):
+		handleTimezone(message, psid, token)
+	case utils.IsHistoryCommand(message):
+		sendExpenseHistory(0, psid, token)
+	case utils.IsSearchFormatCorrect(message):
+		startExpenseSearch(message, psid, token)
+	case utils.IsPayeeListCommand(message):
+		sendPayees(psid, token)
+	case utils.IsAddPayeeFormatCorrect(message):
+		handleAddPayee(message, psid, token)
+	case utils.IsEditPayeeFormatCorrect(message):
+		handleEditPayee(message, psid, token)
+	case utils.IsRenamePayeeFormatCorrect(message):
+		handleRenamePayee(message, psid, token)
+	case utils.IsRemovePayeeFormatCorrect(message):
+		handleRemovePayee(message, psid, token)
+	case utils.IsPayCommandFormatCorrect(message):
+		handlePayCommand(message, psid, token)
+	case utils.IsSplitExpenseFormatCorrect(message):
+		handleSplitExpense(message, psid, token)
+	case utils.IsWhoOwesMeCommand(message):
+		sendOwedBalances(psid, token)
+	case utils.IsSettleFormatCorrect(message):
+		handleSettle(message, psid, token)
+	case utils.IsCreateHouseholdFormatCorrect(message):
+		handleCreateHousehold(message, psid, token)
+	case utils.IsJoinHouseholdFormatCorrect(message):
+		handleJoinHousehold(message, psid, token)
+	case utils.IsLeaveHouseholdCommand(message):
+		handleLeaveHousehold(psid, token)
+	case utils.IsHouseholdInfoCommand(message):
+		sendHouseholdInfo(psid, token)
+	case utils.IsHouseholdReportFormatCorrect(message):
+		sendHouseholdReport(utils.GetHouseholdReportRangeFromMessage(message), psid, token)
+	case utils.IsRecurringExpenseFormatCorrect(message):
+		handleRecurringExpense(message, psid, token)
+	case utils.IsRecurringExpenseListCommand(message):
+		sendRecurringExpenses(psid, token)
+	case utils.IsLoanFormatCorrect(message):
+		handleLoan(message, psid, token)
+	case utils.IsRepaymentFormatCorrect(message):
+		handleRepayment(message, psid, token)
+	case utils.IsDebtSummaryCommand(message):
+		sendDebtSummary(psid, token)
+	case utils.IsDebtHistoryFormatCorrect(message):
+		name, _ := utils.GetDebtHistoryNameFromMessage(message)
+		sendDebtHistory(name, psid, token)
+	case utils.IsSubscriptionCommand(message):
+		sendSubscriptionStatus(psid, token)
+	case utils.IsExportFormatCorrect(message):
+		sendExpenseExport(utils.GetExportRangeFromMessage(message), psid, token)
+	case utils.IsReceiptCommand(message):
+		handleReceiptCommand(psid, token)
+	case utils.IsReceiptListCommand(message):
+		sendReceiptList(psid, token)
+	default:
+		return false
+	}
+
+	setUserState(psid, "WAITING...")
+	return true
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files, such as payment proofs, under string keys. Implementations
+// decide where the bytes live; the database only records the key.
+type BlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+var Blobs BlobStore
+
+// InitBlobStore sets up the blob store from the environment. Files are kept on the local disk
+// under BLOB_STORE_DIR, or ./uploads when it isn't set.
+func InitBlobStore() {
+	dir := os.Getenv("BLOB_STORE_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	store, err := NewLocalBlobStore(dir)
+	if err != nil {
+		log.Fatalf("Failed to set up blob store: %v", err)
+	}
+	Blobs = store
+
+	fmt.Printf("Blob store ready at %s\n", dir)
+}
@@ -34,10 +34,23 @@ var MenuTemplate = map[int]Template{
 		Buttons: []Button{
 			{Type: "postback", Title: "Set Report Sched", Payload: "SET_REPORT_SCHED_SUBMENU"},
 			{Type: "postback", Title: "Reset Sched", Payload: "RESET_SCHED"},
+			{Type: "postback", Title: "Expense Alerts", Payload: "EXPENSE_ALERTS_SETTINGS"},
+		},
+	},
+	5: {
+		Title:    "Savings Goals",
+		Subtitle: "Save up for the things you want",
+		Buttons: []Button{
+			{Type: "postback", Title: "New Goal", Payload: "NEW_GOAL"},
+			{Type: "postback", Title: "Add Savings", Payload: "ADD_GOAL_SAVINGS"},
+			{Type: "postback", Title: "View Goals", Payload: "VIEW_GOALS"},
 		},
 	},
 }
 
+// MainMenuKeys lists the MenuTemplate entries shown together as the top-level menu carousel.
+var MainMenuKeys = []int{1, 5}
+
 var SubMenuTemplate = map[int]Template{
 	1: {
 		Title:    "Generate Financial Report",
@@ -57,4 +70,21 @@ var SubMenuTemplate = map[int]Template{
 			{Type: "postback", Title: "Monthly", Payload: "SET_REPORT_MONTHLY"},
 		},
 	},
+	3: {
+		Title:    "More Payment Reminders",
+		Subtitle: "Follow up on bills that need attention",
+		Buttons: []Button{
+			{Type: "postback", Title: "View Overdue Payments", Payload: "VIEW_OVERDUE_PAYMENTS"},
+		},
+	},
 }
+
+var SensitivityQuickReplies = []QuickReply{
+	{ContentType: "text", Title: "Off", Payload: "SET_SENSITIVITY_OFF"},
+	{ContentType: "text", Title: "Low", Payload: "SET_SENSITIVITY_LOW"},
+	{ContentType: "text", Title: "Medium", Payload: "SET_SENSITIVITY_MEDIUM"},
+	{ContentType: "text", Title: "High", Payload: "SET_SENSITIVITY_HIGH"},
+}
+
+// PaymentCategories are offered when a paid reminder has no expense category yet.
+var PaymentCategories = []string{"Bills", "Rent", "Utilities", "Loans", "Subscriptions", "Other"}
@@ -24,6 +24,12 @@ type Attachment struct {
 	Payload AttachmentPayload `json:"payload"`
 }
 
+type QuickReply struct {
+	ContentType string `json:"content_type"`
+	Title       string `json:"title"`
+	Payload     string `json:"payload"`
+}
+
 type Recipient struct {
 	ID string `json:"id"`
 }
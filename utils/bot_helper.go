@@ -7,7 +7,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"quickyexpensetracker/templates"
 )
 
@@ -104,6 +107,55 @@ func SendTextMessage(message string, PSID string, pageAccessToken string) error
 	return nil
 }
 
+type QuickReplyMessage struct {
+	Text         string                 `json:"text"`
+	QuickReplies []templates.QuickReply `json:"quick_replies"`
+}
+
+type QuickReplyPayload struct {
+	Recipient     templates.Recipient `json:"recipient"`
+	Message       QuickReplyMessage   `json:"message"`
+	MessagingType string              `json:"messaging_type"`
+}
+
+// SendQuickReplies sends a text message with quick reply buttons attached.
+// Tapping a quick reply comes back to the webhook as a PAYLOAD command.
+func SendQuickReplies(message string, quickReplies []templates.QuickReply, PSID string, pageAccessToken string) error {
+	client := &http.Client{}
+
+	payload := QuickReplyPayload{
+		Recipient:     templates.Recipient{ID: PSID},
+		Message:       QuickReplyMessage{Text: message, QuickReplies: quickReplies},
+		MessagingType: "RESPONSE",
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %v", err)
+	}
+
+	url := fmt.Sprintf("https://graph.facebook.com/v21.0/me/messages?access_token=%s", pageAccessToken)
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // SendTemplateMessage sends a generic template message with the provided elements
 func SendTemplateMessage(elements []templates.Template, PSID string, pageAccessToken string) error {
 	payload := templates.RequestPayload{
@@ -180,3 +232,112 @@ func convertToGenericElements(templates []templates.Template) []interface{} {
 	}
 	return elements
 }
+
+// SendImageAttachment uploads an image and sends it to the user as an attachment.
+func SendImageAttachment(image []byte, filename string, mimeType string, PSID string, pageAccessToken string) error {
+	return sendAttachment("image", image, filename, mimeType, PSID, pageAccessToken)
+}
+
+// SendFileAttachment uploads a file, such as a CSV export, and sends it to the user as a download.
+func SendFileAttachment(data []byte, filename string, mimeType string, PSID string, pageAccessToken string) error {
+	return sendAttachment("file", data, filename, mimeType, PSID, pageAccessToken)
+}
+
+// sendAttachment uploads data with the Send API and delivers it as an attachment of the given
+// type ("image" or "file").
+func sendAttachment(attachmentType string, data []byte, filename string, mimeType string, PSID string, pageAccessToken string) error {
+	recipient, err := json.Marshal(templates.Recipient{ID: PSID})
+	if err != nil {
+		return fmt.Errorf("failed to encode recipient: %v", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("recipient", string(recipient))
+	writer.WriteField("message", fmt.Sprintf(`{"attachment":{"type":%q,"payload":{"is_reusable":false}}}`, attachmentType))
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="filedata"; filename="%s"`, filename))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create file part: %v", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %v", attachmentType, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish request body: %v", err)
+	}
+
+	url := fmt.Sprintf("https://graph.facebook.com/v21.0/me/messages?access_token=%s", pageAccessToken)
+	req, err := http.NewRequest("POST", url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// MaxAttachmentSize is the largest attachment accepted from Messenger, which caps files at 25 MB.
+const MaxAttachmentSize = 25 << 20
+
+// DownloadAttachment fetches an attachment a user sent from its Messenger CDN URL and returns
+// its bytes and sniffed content type.
+func DownloadAttachment(url string) ([]byte, string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, "", fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read attachment: %v", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, "", fmt.Errorf("attachment is larger than %d bytes", MaxAttachmentSize)
+	}
+
+	// The CDN doesn't always label images correctly, so go by the bytes
+	contentType := http.DetectContentType(data)
+	return data, contentType, nil
+}
+
+// GetUserFirstName looks up the first name of a user from their Messenger profile.
+func GetUserFirstName(PSID string, pageAccessToken string) (string, error) {
+	url := fmt.Sprintf("https://graph.facebook.com/v21.0/%s?fields=first_name&access_token=%s", PSID, pageAccessToken)
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var profile struct {
+		FirstName string `json:"first_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return "", fmt.Errorf("failed to decode profile: %v", err)
+	}
+	return profile.FirstName, nil
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PersonDebt is the running balance between the user and a person. A positive balance is owed
+// to the user (a receivable); a negative one is owed by the user (a payable).
+type PersonDebt struct {
+	Name    string
+	Balance float64
+}
+
+// LoanInput is what a "lent" or "borrowed" message describes.
+type LoanInput struct {
+	Lent    bool // false for money the user borrowed
+	Amount  float64
+	Person  string
+	DueDate *time.Time
+	HasTime bool // DueDate carries a time of day
+}
+
+var loanMessagePattern = regexp.MustCompile(`(?i)^\s*(lent|borrowed)\s+(\d+(?:\.\d{1,2})?)\s+(to|from)\s+(.+?)(?:\s+due\s+(.+?))?\s*$`)
+
+// GetLoanDataFromMessage parses "lent [amount] to [name] due [month/day]" and
+// "borrowed [amount] from [name] due [month/day]". The due date is optional.
+func GetLoanDataFromMessage(message string) (input LoanInput, err error) {
+	matches := loanMessagePattern.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: lent [amount] to [name] or borrowed [amount] from [name]")
+		return
+	}
+
+	input.Lent = strings.EqualFold(matches[1], "lent")
+	if input.Lent != strings.EqualFold(matches[3], "to") {
+		err = fmt.Errorf("use \"lent [amount] to [name]\" or \"borrowed [amount] from [name]\"")
+		return
+	}
+
+	input.Amount, err = strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+		return
+	}
+	input.Person = strings.TrimSpace(matches[4])
+
+	if matches[5] != "" {
+		dueDate, hasTime, dateErr := ParseReminderDueDate(matches[5])
+		if dateErr != nil {
+			err = dateErr
+			return
+		}
+		input.DueDate = &dueDate
+		input.HasTime = hasTime
+	}
+	return
+}
+
+var repaidToMePattern = regexp.MustCompile(`(?i)^\s*(.+?)\s+paid\s+(?:me\s+)?back\s+(\d+(?:\.\d{1,2})?)\s*$`)
+var repaidByMePattern = regexp.MustCompile(`(?i)^\s*(?:i\s+)?paid\s+(?:back\s+(.+?)|(.+?)\s+back)\s+(\d+(?:\.\d{1,2})?)\s*$`)
+
+// GetRepaymentDataFromMessage parses "[name] paid back [amount]" for money paid back to the
+// user, and "paid back [name] [amount]" for money the user paid back.
+func GetRepaymentDataFromMessage(message string) (person string, amount float64, toMe bool, err error) {
+	var amountText string
+	if matches := repaidByMePattern.FindStringSubmatch(message); matches != nil {
+		person, amountText = matches[1]+matches[2], matches[3]
+	} else if matches := repaidToMePattern.FindStringSubmatch(message); matches != nil {
+		person, amountText, toMe = matches[1], matches[2], true
+	} else {
+		err = fmt.Errorf("invalid format, expected: [name] paid back [amount] or paid back [name] [amount]")
+		return
+	}
+
+	person = strings.TrimSpace(person)
+	amount, err = strconv.ParseFloat(amountText, 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+	}
+	return
+}
+
+// GetDebtHistoryNameFromMessage parses "debts with [name]" or "utang [name]".
+func GetDebtHistoryNameFromMessage(message string) (string, error) {
+	re := regexp.MustCompile(`(?i)^\s*(?:debts?|utang|ious?)\s+(?:with\s+)?(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		return "", fmt.Errorf("invalid format, expected: debts with [name]")
+	}
+	return matches[1], nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetLoanDataFromMessage(t *testing.T) {
+	due := time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC)
+	dueAt := due.Add(17 * time.Hour)
+	tests := []struct {
+		message string
+		want    LoanInput
+		wantErr bool
+	}{
+		{message: "lent 500 to ana", want: LoanInput{Lent: true, Amount: 500, Person: "ana"}},
+		{message: "BORROWED 1000.50 FROM BEN", want: LoanInput{Amount: 1000.50, Person: "BEN"}},
+		{message: "lent 300 to ana marie due 12/25/2026", want: LoanInput{Lent: true, Amount: 300, Person: "ana marie", DueDate: &due}},
+		{message: "borrowed 200 from ben due 12/25/2026 at 5pm", want: LoanInput{Amount: 200, Person: "ben", DueDate: &dueAt, HasTime: true}},
+		{message: "lent 500 from ana", wantErr: true},
+		{message: "borrowed 500 to ana", wantErr: true},
+		{message: "lent five to ana", wantErr: true},
+		{message: "lent 500 to ana due someday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := GetLoanDataFromMessage(tt.message)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GetLoanDataFromMessage(%q) returned no error", tt.message)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetLoanDataFromMessage(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		sameDue := (got.DueDate == nil) == (tt.want.DueDate == nil) && (got.DueDate == nil || got.DueDate.Equal(*tt.want.DueDate))
+		if got.Lent != tt.want.Lent || got.Amount != tt.want.Amount || got.Person != tt.want.Person || got.HasTime != tt.want.HasTime || !sameDue {
+			t.Errorf("GetLoanDataFromMessage(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestGetRepaymentDataFromMessage(t *testing.T) {
+	tests := []struct {
+		message    string
+		wantPerson string
+		wantAmount float64
+		wantToMe   bool
+		wantErr    bool
+	}{
+		{message: "ana paid back 200", wantPerson: "ana", wantAmount: 200, wantToMe: true},
+		{message: "ANA MARIE PAID ME BACK 150.50", wantPerson: "ANA MARIE", wantAmount: 150.50, wantToMe: true},
+		{message: "paid back ben 300", wantPerson: "ben", wantAmount: 300},
+		{message: "I PAID BEN BACK 300", wantPerson: "BEN", wantAmount: 300},
+		{message: "ana paid 200", wantErr: true},
+		{message: "paid back ben", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		person, amount, toMe, err := GetRepaymentDataFromMessage(tt.message)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GetRepaymentDataFromMessage(%q) returned no error", tt.message)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetRepaymentDataFromMessage(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		if person != tt.wantPerson || amount != tt.wantAmount || toMe != tt.wantToMe {
+			t.Errorf("GetRepaymentDataFromMessage(%q) = %q, %v, %v, want %q, %v, %v", tt.message, person, amount, toMe, tt.wantPerson, tt.wantAmount, tt.wantToMe)
+		}
+	}
+}
+
+func TestGetDebtHistoryNameFromMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+		wantErr bool
+	}{
+		{message: "debts with ana", want: "ana"},
+		{message: "DEBT WITH ANA MARIE", want: "ANA MARIE"},
+		{message: "utang ben", want: "ben"},
+		{message: "ious with carlo", want: "carlo"},
+		{message: "debts", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := GetDebtHistoryNameFromMessage(tt.message)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GetDebtHistoryNameFromMessage(%q) returned no error", tt.message)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("GetDebtHistoryNameFromMessage(%q) = %q, %v, want %q", tt.message, got, err, tt.want)
+		}
+	}
+}
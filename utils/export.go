@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"quickyexpensetracker/models"
+	"strconv"
+	"time"
+)
+
+// BuildExpensesCSV renders expenses as a CSV file with one row per expense, dated in loc.
+func BuildExpensesCSV(expenses []models.ExpensesLog, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	rows := [][]string{{"Date", "Category", "Amount"}}
+	for _, expense := range expenses {
+		rows = append(rows, []string{
+			expense.CreatedAt.In(loc).Format("2006-01-02 15:04"),
+			expense.Category,
+			strconv.FormatFloat(expense.Amount, 'f', 2, 64),
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return buf.Bytes(), nil
+}
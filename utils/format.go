@@ -9,7 +9,204 @@ func IsExpenseLogFormatCorrect(text string) bool {
 }
 
 func IsReminderLogFormatCorrect(text string) bool {
-	pattern := `(?i)^\s*(\d+(\.\d{1,2})?)\s+to\s+(.+?):(09\d{9})\s+on\s+(\d{2}/\d{2}/\d{4})\s*$`
+	pattern := `(?i)^\s*(\d+(\.\d{1,2})?)\s+to\s+(.+?)(:(09\d{9})|\s+via\s+(.+?))\s+on\s+(\d{2}/\d{2}/\d{4})(\s+at\s+\d{1,2}(:\d{2})?\s*(am|pm)?)?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+// IsGcashNumberCorrect checks for an 11-digit mobile number starting with 09.
+func IsGcashNumberCorrect(text string) bool {
+	pattern := `^\s*09\d{9}\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsGoalFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*goal\s+(\d+(\.\d{1,2})?)\s+for\s+(.+?)(\s+by\s+(\d{1,2}/\d{4}))?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsGoalContributionFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*save\s+(\d+(\.\d{1,2})?)\s+to\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsHistoryCommand(text string) bool {
+	pattern := `(?i)^\s*history\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsSearchFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*search\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsDefaultLeadDaysFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*notify\s+me\s+((\d+\s*,\s*)*\d+)\s+days?\s+before\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsReminderTimeFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*remind\s+me\s+at\s+(\d{1,2}(:\d{2})?\s*(am|pm)?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsTimezoneFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*timezone\s+(\S+)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+// IsPayCommandFormatCorrect matches "pay [payee] [amount] on [date]" and anything after the
+// date, such as a recurrence or advance notice, which is validated separately.
+func IsPayCommandFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*pay\s+\S.*?\s+on\s+\d{1,2}/\d{1,2}(/\d{4})?\b.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsPayeeListCommand(text string) bool {
+	pattern := `(?i)^\s*(list\s+)?payees\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsAddPayeeFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*add\s+payee\s+\S.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsEditPayeeFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*edit\s+payee\s+\S.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsRenamePayeeFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*rename\s+payee\s+(.+?)\s+to\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsRemovePayeeFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*(remove|delete)\s+payee\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsSplitExpenseFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*(\d+(\.\d{1,2})?)\s+for\s+(.+?)\s+split\s+with\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsWhoOwesMeCommand(text string) bool {
+	pattern := `(?i)^\s*who\s+owes\s+me\s*\??\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsSettleFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*settle\s+(.+?)(\s+(\d+(\.\d{1,2})?))?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsCreateHouseholdFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*create\s+household\s+(.+?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsJoinHouseholdFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*join\s+household\s+([a-z0-9]+)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsLeaveHouseholdCommand(text string) bool {
+	pattern := `(?i)^\s*leave\s+household\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsHouseholdInfoCommand(text string) bool {
+	pattern := `(?i)^\s*(my\s+)?household\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsHouseholdReportFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*household\s+report(\s+(day|week|month))?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsLoanFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*(lent\s+\d+(\.\d{1,2})?\s+to|borrowed\s+\d+(\.\d{1,2})?\s+from)\s+\S.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsRepaymentFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*(.+?\s+paid\s+(me\s+)?back|(i\s+)?paid\s+(back\s+.+?|.+?\s+back))\s+\d+(\.\d{1,2})?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsDebtSummaryCommand(text string) bool {
+	pattern := `(?i)^\s*(debts|utang|ious?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsDebtHistoryFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*(debts?|utang|ious?)\s+(with\s+)?\S.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+// IsRecurringExpenseFormatCorrect matches "recurring [amount] for [item] on [date]" and the
+// schedule after it, which is validated separately.
+func IsRecurringExpenseFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*recurring\s+\d+(\.\d{1,2})?\s+for\s+.+?\s+on\s+\d{1,2}/\d{1,2}(/\d{4})?\b.*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsRecurringExpenseListCommand(text string) bool {
+	pattern := `(?i)^\s*recurring(\s+expenses)?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsSubscriptionCommand(text string) bool {
+	pattern := `(?i)^\s*(my\s+)?(subscription|premium|plans?)\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsExportFormatCorrect(text string) bool {
+	pattern := `(?i)^\s*export(\s+(day|week|month))?\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsReceiptCommand(text string) bool {
+	pattern := `(?i)^\s*(add\s+)?receipt\s*$`
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(text)
+}
+
+func IsReceiptListCommand(text string) bool {
+	pattern := `(?i)^\s*(my\s+)?receipts\s*$`
 	re := regexp.MustCompile(pattern)
 	return re.MatchString(text)
 }
@@ -2,35 +2,190 @@ package utils
 
 import (
 	"fmt"
+	"math"
+	"quickyexpensetracker/billing"
 	"quickyexpensetracker/models"
 	"quickyexpensetracker/templates"
+	"sort"
 	"strings"
+	"time"
 )
 
+// MessengerTextLimit is the maximum number of characters the Send API accepts in a text message.
+const MessengerTextLimit = 2000
+
+// ReportTopN is the number of categories listed on each page of an expense report.
+const ReportTopN = 8
+
+type CategoryTotal struct {
+	Category string
+	Amount   float64
+}
+
+// SortedCategoryTotals sums expenses per category and orders them by amount, largest first.
+// Ties are broken alphabetically so the order is stable between reports.
+func SortedCategoryTotals(expenses []models.ExpensesLog) []CategoryTotal {
+	sums := make(map[string]float64)
+	for _, exp := range expenses {
+		sums[exp.Category] += exp.Amount
+	}
+
+	totals := make([]CategoryTotal, 0, len(sums))
+	for category, amount := range sums {
+		totals = append(totals, CategoryTotal{Category: category, Amount: amount})
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Amount != totals[j].Amount {
+			return totals[i].Amount > totals[j].Amount
+		}
+		return totals[i].Category < totals[j].Category
+	})
+
+	return totals
+}
+
 func GetExpenseReport(expenses []models.ExpensesLog, rangeDay string) string {
+	report, _ := GetExpenseReportPage(expenses, rangeDay, 0)
+	return report
+}
+
+// GetExpenseReportPage renders one page of an expense report. Page 0 carries the header
+// and the top categories, with everything else folded into an "Other" line; later pages
+// list the remaining categories ReportTopN at a time. hasMore reports whether a further page exists.
+func GetExpenseReportPage(expenses []models.ExpensesLog, rangeDay string, page int) (report string, hasMore bool) {
 	var total float64 = 0
-	categoryTotals := make(map[string]float64)
+	for _, exp := range expenses {
+		total += exp.Amount
+	}
+
+	totals := SortedCategoryTotals(expenses)
+
+	start := page * ReportTopN
+	if start > len(totals) {
+		start = len(totals)
+	}
+	end := start + ReportTopN
+	if end > len(totals) {
+		end = len(totals)
+	}
+	hasMore = end < len(totals)
+
+	if page == 0 {
+		report = fmt.Sprintf("%v Report\n", rangeDay)
+		report += fmt.Sprintf("Total: %.2f\n", total)
+	} else {
+		report = fmt.Sprintf("%v Report (continued)\n", rangeDay)
+	}
+
+	for _, ct := range totals[start:end] {
+		report += formatCategoryLine(ct.Category, ct.Amount, total)
+	}
+
+	if page == 0 && hasMore {
+		var otherAmount float64
+		for _, ct := range totals[end:] {
+			otherAmount += ct.Amount
+		}
+		report += formatCategoryLine(fmt.Sprintf("Other (%d categories)", len(totals)-end), otherAmount, total)
+	}
+
+	return report, hasMore
+}
+
+func formatCategoryLine(category string, amount float64, total float64) string {
+	var percentage float64
+	if total > 0 {
+		percentage = (amount / total) * 100
+	} else {
+		percentage = 0 // Or handle as appropriate, e.g. display N/A
+	}
+	return fmt.Sprintf("%s = ₱%.2f - %.2f%%\n", category, amount, percentage)
+}
+
+// SplitMessage breaks text into chunks no longer than limit characters, preferring to
+// split on line breaks. Lines longer than the limit are split mid-line.
+func SplitMessage(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if currentLen > 0 {
+			chunks = append(chunks, strings.TrimRight(current.String(), "\n"))
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > limit {
+			flush()
+			chunks = append(chunks, string(runes[:limit]))
+			runes = runes[limit:]
+		}
+		if currentLen+len(runes) > limit {
+			flush()
+		}
+		current.WriteString(string(runes))
+		currentLen += len(runes)
+	}
+	flush()
+
+	return chunks
+}
+
+// CategoryItemsPageSize is the number of expenses listed per page when drilling into a category.
+const CategoryItemsPageSize = 10
 
-	// Sum total and category totals
+// GetCategoryCards renders an expense report as one generic-template card per category,
+// largest first, each with a "View items" postback that drills into its expenses.
+func GetCategoryCards(expenses []models.ExpensesLog, rangeType string) []templates.Template {
+	var total float64
+	counts := make(map[string]int)
 	for _, exp := range expenses {
 		total += exp.Amount
-		categoryTotals[exp.Category] += exp.Amount
+		counts[exp.Category]++
 	}
 
-	// Build the report
-	report := fmt.Sprintf("%v Report\n", rangeDay)
-	report += fmt.Sprintf("Total: %.2f\n", total)
-	for category, amount := range categoryTotals {
+	var cards []templates.Template
+	for _, ct := range SortedCategoryTotals(expenses) {
 		var percentage float64
 		if total > 0 {
-			percentage = (amount / total) * 100
-		} else {
-			percentage = 0 // Or handle as appropriate, e.g. display N/A
+			percentage = (ct.Amount / total) * 100
 		}
-		report += fmt.Sprintf("%s = ₱%.2f - %.2f%%\n", category, amount, percentage)
+
+		cards = append(cards, templates.Template{
+			Title:    ct.Category,
+			Subtitle: fmt.Sprintf("₱%.2f - %.2f%% of ₱%.2f\n%d item(s)", ct.Amount, percentage, total, counts[ct.Category]),
+			Buttons: []templates.Button{
+				{
+					Type:    "postback",
+					Title:   "View items",
+					Payload: fmt.Sprintf("VIEW_CATEGORY_%s_%s", ct.Category, strings.ToUpper(rangeType)),
+				},
+			},
+		})
 	}
 
-	return report
+	return cards
+}
+
+// GetCategoryItemsMessage lists one page of the expenses that make up a category total.
+func GetCategoryItemsMessage(category string, rangeDay string, expenses []models.ExpensesLog, page int, totalCount int64) string {
+	if totalCount == 0 {
+		return fmt.Sprintf("No %s expenses in this %s report.", category, strings.ToLower(rangeDay))
+	}
+
+	first := page*CategoryItemsPageSize + 1
+	last := first + len(expenses) - 1
+	message := fmt.Sprintf("%s (%s) - items %d-%d of %d\n", category, rangeDay, first, last, totalCount)
+	for _, exp := range expenses {
+		message += fmt.Sprintf("• ₱%.2f - %s\n", exp.Amount, exp.CreatedAt.Format("Jan 2, 3:04 PM"))
+	}
+
+	return message
 }
 
 func GetRemindersReport(reminders []models.RemindersLog) []templates.Template {
@@ -42,24 +197,50 @@ func GetRemindersReport(reminders []models.RemindersLog) []templates.Template {
 
 	for _, reminder := range reminders {
 		title := fmt.Sprintf("Payment to %s", reminder.Recipient)
-		subtitle := fmt.Sprintf("Amount: ₱%.2f\nGCash: %s\nDue: %s",
+		subtitle := fmt.Sprintf("Amount: ₱%.2f\n%s\nDue: %s",
 			reminder.Amount,
-			reminder.GcashNumber,
-			reminder.DueDate.Format("2006-01-02"))
+			DescribePaymentAccount(reminder),
+			FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+
+		if schedule := DescribeSchedule(reminder.Frequency, reminder.RRule); schedule != "One-time" {
+			subtitle += "\n" + schedule
+		}
+
+		if reminder.Status == models.ReminderOverdue {
+			title = fmt.Sprintf("Overdue: Payment to %s", reminder.Recipient)
+			subtitle += fmt.Sprintf("\nOverdue by %d day(s)", -DaysUntil(reminder.DueDate, time.Now()))
+		}
 
 		var buttons []templates.Button
-		if reminder.PaymentMethod == "Gcash" && reminder.Status == "pending" {
+		if reminder.ReminderType == "debt" {
+			title = fmt.Sprintf("Debt with %s", reminder.Recipient)
+			subtitle = fmt.Sprintf("Amount: ₱%.2f\nDue: %s", reminder.Amount, FormatDueDate(reminder.DueDate, reminder.DueTimeSet))
+			buttons = append(buttons, templates.Button{
+				Type:    "postback",
+				Title:   "View Debt",
+				Payload: "VIEW_DEBT_" + fmt.Sprint(reminder.ID),
+			})
 			buttons = append(buttons, templates.Button{
 				Type:    "postback",
-				Title:   "Pay with Gcash",
-				Payload: "PAY_GCASH_" + fmt.Sprint(reminder.ID),
+				Title:   "Delete",
+				Payload: "DELETE_REMINDER_" + fmt.Sprint(reminder.ID),
 			})
+		} else if reminder.ReminderType == "payment" && (reminder.Status == models.ReminderPending || reminder.Status == models.ReminderOverdue) {
+			if payButton, ok := PayButton(reminder); ok {
+				buttons = append(buttons, payButton)
+			}
 			buttons = append(buttons, templates.Button{
 				Type:    "postback",
 				Title:   "Mark as Paid",
 				Payload: "MARK_AS_PAID_" + fmt.Sprint(reminder.ID),
 			})
-		} else if reminder.Status == "completed" || reminder.Status == "paid" {
+			// Cards are limited to three buttons, so edit, reschedule, delete and history sit behind Manage
+			buttons = append(buttons, templates.Button{
+				Type:    "postback",
+				Title:   "Manage",
+				Payload: "MANAGE_REMINDER_" + fmt.Sprint(reminder.ID),
+			})
+		} else if reminder.Status == models.ReminderPaid {
 			title = fmt.Sprintf("Accomplished: Payment to %s", reminder.Recipient)
 			// Subtitle remains the same
 			buttons = []templates.Button{} // Initialize as empty slice
@@ -68,6 +249,16 @@ func GetRemindersReport(reminders []models.RemindersLog) []templates.Template {
 				Title:   "View Details",
 				Payload: fmt.Sprintf("VIEW_ACCOMPLISHED_DETAIL_%d", reminder.ID),
 			})
+			buttons = append(buttons, templates.Button{
+				Type:    "postback",
+				Title:   "History",
+				Payload: "VIEW_REMINDER_HISTORY_" + fmt.Sprint(reminder.ID),
+			})
+			buttons = append(buttons, templates.Button{
+				Type:    "postback",
+				Title:   "Delete",
+				Payload: "DELETE_REMINDER_" + fmt.Sprint(reminder.ID),
+			})
 		}
 
 		element := templates.Template{
@@ -97,3 +288,340 @@ func GenerateExpenseSummaryMessage(frequency string, expenses []models.ExpensesL
 	return fmt.Sprintf("Hi! Here's your %s expense summary: You had %d transaction(s), totaling ₱%.2f.",
 		displayFrequency, len(expenses), totalAmount)
 }
+
+// ProgressBar renders a fixed-width text bar for a fraction between 0 and 1.
+func ProgressBar(fraction float64, width int) string {
+	if fraction < 0 || math.IsNaN(fraction) {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction*float64(width) + 0.5)
+	return strings.Repeat("▓", filled) + strings.Repeat("░", width-filled)
+}
+
+// MonthlyAmountNeeded returns how much must be saved per month, counting the current
+// month, to reach a goal by its target date. It returns 0 for goals without a date
+// or that are already reached.
+func MonthlyAmountNeeded(goal models.SavingsGoal, now time.Time) float64 {
+	remaining := goal.TargetAmount - goal.SavedAmount
+	if goal.TargetDate == nil || remaining <= 0 {
+		return 0
+	}
+
+	months := (goal.TargetDate.Year()-now.Year())*12 + int(goal.TargetDate.Month()-now.Month()) + 1
+	if months < 1 {
+		months = 1 // Past the target date, the rest is due now
+	}
+	return remaining / float64(months)
+}
+
+func GetGoalsReport(goals []models.SavingsGoal, now time.Time) string {
+	if len(goals) == 0 {
+		return "You don't have any savings goals yet.\nCreate one with: goal [amount] for [name] by [month/year]\n(e.g. goal 30000 for laptop by 12/2025)"
+	}
+
+	report := "Your Savings Goals\n"
+	for _, goal := range goals {
+		var fraction float64
+		if goal.TargetAmount > 0 {
+			fraction = goal.SavedAmount / goal.TargetAmount
+		}
+
+		report += fmt.Sprintf("\n%s\n%s %.0f%%\n₱%.2f of ₱%.2f\n", goal.Name, ProgressBar(fraction, 10), fraction*100, goal.SavedAmount, goal.TargetAmount)
+
+		switch {
+		case goal.SavedAmount >= goal.TargetAmount:
+			report += "Goal reached! 🎉\n"
+		case goal.TargetDate != nil:
+			report += fmt.Sprintf("Target: %s - save ₱%.2f/month\n", goal.TargetDate.Format("Jan 2006"), MonthlyAmountNeeded(goal, now))
+		}
+	}
+
+	return report
+}
+
+// ExpenseListPageSize is the number of expenses shown per page of history or search results.
+const ExpenseListPageSize = 10
+
+// GetExpenseListMessage renders one page of individual expenses under a heading.
+func GetExpenseListMessage(heading string, expenses []models.ExpensesLog, offset int, totalCount int64) string {
+	if totalCount == 0 {
+		return fmt.Sprintf("%s\nNo expenses found.", heading)
+	}
+
+	message := fmt.Sprintf("%s (%d-%d of %d)\n", heading, offset+1, offset+len(expenses), totalCount)
+	for _, exp := range expenses {
+		message += fmt.Sprintf("• ₱%.2f %s - %s\n", exp.Amount, exp.Category, exp.CreatedAt.Format("Jan 2, 2006 3:04 PM"))
+	}
+
+	return message
+}
+
+// GetOccurrenceHistoryMessage lists the payment history of a reminder, newest first.
+func GetOccurrenceHistoryMessage(reminder models.RemindersLog, occurrences []models.ReminderOccurrence, now time.Time) string {
+	message := fmt.Sprintf("Payment history for %s (₱%.2f)\n%s\n", reminder.Recipient, reminder.Amount, DescribeSchedule(reminder.Frequency, reminder.RRule))
+	if len(occurrences) == 0 {
+		return message + "No payments are due yet."
+	}
+
+	for _, occurrence := range occurrences {
+		line := fmt.Sprintf("\n• %s - ", FormatDueDate(occurrence.DueDate, reminder.DueTimeSet))
+		switch {
+		case occurrence.Status == models.ReminderPaid && occurrence.PaidAt != nil:
+			line += "Paid " + occurrence.PaidAt.Format("Jan 2")
+		case occurrence.Status == models.ReminderPaid:
+			line += "Paid"
+		case occurrence.Status == models.ReminderSkipped:
+			line += "Skipped"
+		case DaysUntil(occurrence.DueDate, now) < 0:
+			line += fmt.Sprintf("Unpaid (%d day(s) late)", -DaysUntil(occurrence.DueDate, now))
+		default:
+			line += "Unpaid"
+		}
+		message += line
+	}
+
+	return message
+}
+
+// GetPayeesReport lists a user's saved payees with their payment details and default amounts.
+func GetPayeesReport(payees []models.Payee) string {
+	if len(payees) == 0 {
+		return "You don't have any saved payees yet.\nAdd one with: add payee [name] via [payment method] [account] default [amount]\n(e.g. add payee landlord via bdo 0012345678 default 15000)"
+	}
+
+	report := "Your Payees\n"
+	for _, payee := range payees {
+		report += fmt.Sprintf("\n• %s - %s", payee.Name, DescribePaymentDetails(PaymentDetails{
+			Method:        payee.PaymentMethod,
+			AccountNumber: payee.AccountNumber,
+			BankName:      payee.BankName,
+			TransferRail:  payee.TransferRail,
+		}))
+		if payee.DefaultAmount != nil {
+			report += fmt.Sprintf(" (₱%.2f)", *payee.DefaultAmount)
+		}
+	}
+	report += "\n\nSet a reminder with: pay [name] [amount] on [month/day]"
+	return report
+}
+
+// GetOwedBalancesReport summarizes what each person still owes the user, from loans and split
+// expenses alike.
+func GetOwedBalancesReport(debts []PersonDebt) string {
+	var total float64
+	report := "Who owes you\n"
+	for _, debt := range debts {
+		if debt.Balance < 0.005 {
+			continue
+		}
+		total += debt.Balance
+		report += fmt.Sprintf("\n• %s: ₱%.2f", debt.Name, debt.Balance)
+	}
+	if total == 0 {
+		return "No one owes you anything right now. Split an expense with \"1200 for lunch split with ana, ben\", or track a loan with \"lent 500 to ana\"."
+	}
+	report += fmt.Sprintf("\n\nTotal: ₱%.2f\nWhen someone pays you back, type: settle [name] [amount]", total)
+	return report
+}
+
+// GetHouseholdReport renders a household's shared spending for a range, broken down by member
+// and by category. memberNames maps user IDs to the names shown for them.
+func GetHouseholdReport(household models.Household, expenses []models.ExpensesLog, rangeDay string, memberNames map[string]string) string {
+	var total float64
+	byMember := make(map[string]float64)
+	for _, exp := range expenses {
+		total += exp.Amount
+		name, ok := memberNames[exp.UserID]
+		if !ok {
+			name = "Former member"
+		}
+		byMember[name] += exp.Amount
+	}
+
+	report := fmt.Sprintf("%s - %v Household Report\nTotal: ₱%.2f\n", household.Name, rangeDay, total)
+	if len(expenses) == 0 {
+		return report + "\nNo shared expenses yet. After logging an expense, tap \"Add to household\" to share it."
+	}
+
+	members := make([]CategoryTotal, 0, len(byMember))
+	for name, amount := range byMember {
+		members = append(members, CategoryTotal{Category: name, Amount: amount})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Amount != members[j].Amount {
+			return members[i].Amount > members[j].Amount
+		}
+		return members[i].Category < members[j].Category
+	})
+
+	report += "\nBy member\n"
+	for _, member := range members {
+		report += formatCategoryLine(member.Category, member.Amount, total)
+	}
+	report += "\nBy category\n"
+	for _, ct := range SortedCategoryTotals(expenses) {
+		report += formatCategoryLine(ct.Category, ct.Amount, total)
+	}
+	return report
+}
+
+// GetHouseholdInfoMessage describes a household, its invite code and its members.
+func GetHouseholdInfoMessage(household models.Household, members []models.HouseholdMember) string {
+	message := fmt.Sprintf("Household: %s\nInvite code: %s\n\nMembers", household.Name, household.InviteCode)
+	for _, member := range members {
+		message += "\n• " + member.DisplayName
+		if member.UserID == household.OwnerID {
+			message += " (owner)"
+		}
+	}
+	message += "\n\nOthers can join by typing: join household " + household.InviteCode
+	message += "\nType \"household report\" to see shared spending or \"leave household\" to leave."
+	return message
+}
+
+// GetDebtSummaryReport lists outstanding receivables and payables. splitOwed is what others
+// owe from split expenses, which is tracked separately and only totalled here.
+func GetDebtSummaryReport(debts []PersonDebt) string {
+	var receivables, payables string
+	var totalReceivable, totalPayable float64
+	for _, debt := range debts {
+		switch {
+		case debt.Balance >= 0.005:
+			receivables += fmt.Sprintf("\n• %s owes you ₱%.2f", debt.Name, debt.Balance)
+			totalReceivable += debt.Balance
+		case debt.Balance <= -0.005:
+			payables += fmt.Sprintf("\n• You owe %s ₱%.2f", debt.Name, -debt.Balance)
+			totalPayable -= debt.Balance
+		}
+	}
+
+	if receivables == "" && payables == "" {
+		return "No outstanding debts. Track one with \"lent 500 to ana\" or \"borrowed 300 from ben\", and add \"due 06/30\" for a reminder."
+	}
+
+	report := "Utang Summary\n"
+	if receivables != "" {
+		report += fmt.Sprintf("\nOwed to you: ₱%.2f%s\n", totalReceivable, receivables)
+	}
+	if payables != "" {
+		report += fmt.Sprintf("\nYou owe: ₱%.2f%s\n", totalPayable, payables)
+	}
+	report += "\nRecord repayments with \"ana paid back 200\" or \"paid back ben 100\"."
+	return report
+}
+
+// GetDebtHistoryMessage lists the loans and repayments between the user and a person. balance
+// also counts split expenses, which are summed up in a line of their own.
+func GetDebtHistoryMessage(person string, entries []models.DebtEntry, balance float64) string {
+	var entriesBalance float64
+	for _, entry := range entries {
+		switch entry.Kind {
+		case models.DebtLent, models.DebtRepaidByMe:
+			entriesBalance += entry.Amount
+		default:
+			entriesBalance -= entry.Amount
+		}
+	}
+	splitOwed := balance - entriesBalance
+	if len(entries) == 0 && math.Abs(splitOwed) < 0.005 {
+		return fmt.Sprintf("You don't have any debts with %s.", person)
+	}
+
+	message := fmt.Sprintf("Debts with %s\n", person)
+	if math.Abs(splitOwed) >= 0.005 {
+		message += fmt.Sprintf("\nSplit expenses - %s owes you ₱%.2f", person, splitOwed)
+	}
+	for _, entry := range entries {
+		line := fmt.Sprintf("\n%s - ", entry.CreatedAt.Format("Jan 2, 2006"))
+		switch entry.Kind {
+		case models.DebtLent:
+			line += fmt.Sprintf("You lent ₱%.2f", entry.Amount)
+		case models.DebtBorrowed:
+			line += fmt.Sprintf("You borrowed ₱%.2f", entry.Amount)
+		case models.DebtRepaidToMe:
+			line += fmt.Sprintf("Paid you back ₱%.2f", entry.Amount)
+		case models.DebtRepaidByMe:
+			line += fmt.Sprintf("You paid back ₱%.2f", entry.Amount)
+		}
+		if entry.DueDate != nil {
+			line += fmt.Sprintf(" (due %s)", entry.DueDate.Format("Jan 2, 2006"))
+		}
+		message += line
+	}
+
+	switch {
+	case balance >= 0.005:
+		message += fmt.Sprintf("\n\nBalance: %s owes you ₱%.2f", person, balance)
+	case balance <= -0.005:
+		message += fmt.Sprintf("\n\nBalance: You owe %s ₱%.2f", person, -balance)
+	default:
+		message += "\n\nAll settled!"
+	}
+	return message
+}
+
+// GetRecurringExpensesReport lists a user's recurring expenses with their schedules.
+func GetRecurringExpensesReport(recurringExpenses []models.RecurringExpense) string {
+	if len(recurringExpenses) == 0 {
+		return "You don't have any recurring expenses yet. Set one up with: recurring [amount] for [item] on [month/day] every [schedule]\n(e.g. recurring 549 for netflix on 06/05 every month)"
+	}
+
+	report := "Your Recurring Expenses\n"
+	for _, recurring := range recurringExpenses {
+		report += fmt.Sprintf("\n• ₱%.2f for %s - %s", recurring.Amount, recurring.Category, DescribeSchedule("", recurring.RRule))
+		if recurring.NextRunAt != nil {
+			report += fmt.Sprintf("\n  Next: %s", FormatDueDate(*recurring.NextRunAt, false))
+		} else {
+			report += "\n  Schedule ended"
+		}
+	}
+	return report
+}
+
+// GetSubscriptionStatusMessage describes the user's subscription, or the free plan when there
+// is none. live is whether the subscription still grants its features.
+func GetSubscriptionStatusMessage(subscription *models.Subscription, live bool) string {
+	if subscription == nil || !live {
+		message := "You're on the free plan."
+		if subscription != nil {
+			message = fmt.Sprintf("Your Premium access ended on %s. You're on the free plan.", FormatDueDate(subscription.CurrentPeriodEnd, false))
+		}
+		return message + " Premium lets you export your expenses as a CSV file. Pick a plan below."
+	}
+
+	planName := subscription.PlanKey
+	if plan, ok := billing.GetPlan(subscription.PlanKey); ok {
+		planName = plan.Name
+	}
+	ends := FormatDueDate(subscription.CurrentPeriodEnd, false)
+	switch {
+	case subscription.Status == models.SubscriptionTrialing && subscription.CancelAtPeriodEnd:
+		return fmt.Sprintf("You're on a free trial of %s. It ends on %s and won't be continued.", planName, ends)
+	case subscription.Status == models.SubscriptionTrialing:
+		return fmt.Sprintf("You're on a free trial of %s until %s. Subscribe before then to keep Premium.", planName, ends)
+	case subscription.CancelAtPeriodEnd:
+		return fmt.Sprintf("You're on %s until %s. It's cancelled, so it won't be renewed.", planName, ends)
+	default:
+		return fmt.Sprintf("You're on %s until %s. I'll remind you before it's time to renew.", planName, ends)
+	}
+}
+
+// GetReceiptsReport lists expenses that have receipt photos attached, with how many each has.
+func GetReceiptsReport(expenses []models.ExpensesLog, receiptCounts map[uint]int) string {
+	if len(expenses) == 0 {
+		return "You haven't attached any receipts yet. Tap \"Add receipt\" after logging an expense, or reply \"receipt\" to attach one to your latest expense."
+	}
+
+	report := "Expenses with Receipts\n"
+	for _, expense := range expenses {
+		photos := "photo"
+		if receiptCounts[expense.ID] != 1 {
+			photos = "photos"
+		}
+		report += fmt.Sprintf("\n• ₱%.2f for %s on %s (%d %s)", expense.Amount, expense.Category, expense.CreatedAt.Format("2006-01-02"), receiptCounts[expense.ID], photos)
+	}
+	return report + "\n\nTap an expense to view its receipts."
+}
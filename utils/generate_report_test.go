@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{name: "empty", text: "", limit: 10, want: nil},
+		{name: "fits in one message", text: "Total: ₱100.00", limit: 20, want: []string{"Total: ₱100.00"}},
+		{name: "splits on line breaks", text: "aaa\nbbb\nccc", limit: 8, want: []string{"aaa\nbbb", "ccc"}},
+		{name: "long line split mid-line", text: "abcdefghij", limit: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "long line after a short one", text: "ab\ncdefgh", limit: 4, want: []string{"ab", "cdef", "gh"}},
+		{name: "counts characters, not bytes", text: "₱₱₱₱₱", limit: 2, want: []string{"₱₱", "₱₱", "₱"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitMessage(tt.text, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SplitMessage(%q, %d) = %q, want %q", tt.name, tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMessageKeepsReportsWithinMessengerLimit(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 300; i++ {
+		lines = append(lines, fmt.Sprintf("• Expense %03d: ₱1,234.00 on groceries", i))
+	}
+	report := strings.Join(lines, "\n")
+
+	chunks := SplitMessage(report, MessengerTextLimit)
+	if len(chunks) < 2 {
+		t.Fatalf("SplitMessage returned %d chunk(s) for a %d-character report", len(chunks), utf8.RuneCountInString(report))
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > MessengerTextLimit {
+			t.Errorf("chunk %d has %d characters, over the %d limit", i, n, MessengerTextLimit)
+		}
+	}
+	if joined := strings.Join(chunks, "\n"); joined != report {
+		t.Errorf("chunks don't add back up to the report: split mid-line or dropped text")
+	}
+}
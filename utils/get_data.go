@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -25,39 +26,547 @@ func GetExpenseDataFromMessage(message string) (amount float64, category string,
 	return
 }
 
-func GetReminderDataFromMessage(message string) (amount float64, accountName string, gcashNumber string, date time.Time, err error) {
-	parts := strings.Split(message, " to ")
+// ReminderDetails is what a reminder message describes.
+type ReminderDetails struct {
+	Amount    float64
+	Recipient string
+	Payment   PaymentDetails
+	DueDate   time.Time
+	HasTime   bool // DueDate carries a time of day
+}
+
+var reminderViaPattern = regexp.MustCompile(`(?i)\s+via\s+`)
+
+// GetReminderDataFromMessage parses "[amount] to [name] via [method] [account] on [MM/DD/YYYY] at [time]",
+// or the GCash shorthand "[amount] to [name]:[number] on [MM/DD/YYYY] at [time]". The time is optional.
+func GetReminderDataFromMessage(message string) (details ReminderDetails, err error) {
+	parts := strings.SplitN(message, " to ", 2)
 	if len(parts) != 2 {
 		err = fmt.Errorf("invalid format: missing 'to'")
 		return
 	}
 	amountString := strings.TrimSpace(parts[0])
-	amount, err = strconv.ParseFloat((amountString), 64)
+	details.Amount, err = strconv.ParseFloat((amountString), 64)
 	if err != nil {
 		err = fmt.Errorf("invalid amount format")
 		return
 	}
 
-	secondParts := strings.Split(parts[1], " on ")
-	if len(secondParts) != 2 {
+	onIndex := strings.LastIndex(parts[1], " on ")
+	if onIndex < 0 {
 		err = fmt.Errorf("invalid format: missing 'on'")
 		return
 	}
+	recipient, when := parts[1][:onIndex], parts[1][onIndex+len(" on "):]
+
+	if via := reminderViaPattern.FindStringIndex(recipient); via != nil {
+		details.Recipient = strings.TrimSpace(recipient[:via[0]])
+		details.Payment, err = ParsePaymentMethod(recipient[via[1]:], details.Amount)
+		if err != nil {
+			return
+		}
+	} else {
+		nameAndNumber := strings.Split(recipient, ":")
+		if len(nameAndNumber) != 2 {
+			err = fmt.Errorf("invalid format: use [name]:[gcash number] or [name] via [payment method]")
+			return
+		}
+		details.Recipient = strings.TrimSpace(nameAndNumber[0])
+		details.Payment = PaymentDetails{Method: "Gcash", AccountNumber: strings.TrimSpace(nameAndNumber[1])}
+	}
+
+	details.DueDate, details.HasTime, err = ParseReminderDueDate(when)
+	return
+}
+
+// ParseReminderDueDate parses "[MM/DD/YYYY] at [time]" where the time is optional; hasTime
+// reports whether one was given, in which case date carries it. The year may be left out
+// ("06/01"), in which case the next such date from today is used.
+func ParseReminderDueDate(text string) (date time.Time, hasTime bool, err error) {
+	dateAndTime := strings.SplitN(strings.TrimSpace(text), " at ", 2)
+
+	day := strings.TrimSpace(dateAndTime[0])
+	date, err = time.Parse("01/02/2006", day)
+	if err != nil {
+		date, err = time.Parse("1/2", day)
+		if err != nil {
+			err = fmt.Errorf("invalid date format, expected MM/DD/YYYY")
+			return
+		}
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		date = time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if date.Before(today) {
+			date = date.AddDate(1, 0, 0)
+		}
+	}
+
+	if len(dateAndTime) == 2 {
+		hour, minute, timeErr := ParseTimeOfDay(dateAndTime[1])
+		if timeErr != nil {
+			err = fmt.Errorf("invalid time format, expected e.g. 9am or 21:00")
+			return
+		}
+		date = date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		hasTime = true
+	}
+
+	return
+}
+
+// GetGoalDataFromMessage parses "goal [amount] for [name] by [MM/YYYY]". The target date is
+// optional; when present it is the last moment of the given month.
+func GetGoalDataFromMessage(message string) (amount float64, name string, targetDate *time.Time, err error) {
+	re := regexp.MustCompile(`(?i)^\s*goal\s+(\d+(?:\.\d{1,2})?)\s+for\s+(.+?)(?:\s+by\s+(\d{1,2}/\d{4}))?\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: goal [amount] for [name] by [month/year]")
+		return
+	}
+
+	amount, err = strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+		return
+	}
+	name = strings.TrimSpace(matches[2])
+
+	if matches[3] != "" {
+		month, parseErr := time.Parse("1/2006", matches[3])
+		if parseErr != nil {
+			err = fmt.Errorf("invalid date format, expected MM/YYYY")
+			return
+		}
+		endOfMonth := month.AddDate(0, 1, 0).Add(-time.Second)
+		targetDate = &endOfMonth
+	}
+
+	return
+}
+
+// GetGoalContributionDataFromMessage parses "save [amount] to [goal name]".
+func GetGoalContributionDataFromMessage(message string) (amount float64, goalName string, err error) {
+	re := regexp.MustCompile(`(?i)^\s*save\s+(\d+(?:\.\d{1,2})?)\s+to\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: save [amount] to [goal name]")
+		return
+	}
+
+	amount, err = strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+		return
+	}
+	goalName = strings.TrimSpace(matches[2])
 
-	nameAndNumber := strings.Split(secondParts[0], ":")
-	if len(nameAndNumber) != 2 {
-		err = fmt.Errorf("invalid format: missing ':' between name and number")
+	return
+}
+
+// GetSearchDataFromMessage parses "search [text] [amount filter]". The amount filter is an
+// optional last word: a range (100-500), a bound (>1000 or <50) or an exact amount (350).
+func GetSearchDataFromMessage(message string) (text string, minAmount *float64, maxAmount *float64, err error) {
+	re := regexp.MustCompile(`(?i)^\s*search\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: search [text] [amount range]")
 		return
 	}
 
-	accountName = strings.TrimSpace(nameAndNumber[0])
-	gcashNumber = strings.TrimSpace(nameAndNumber[1])
+	words := strings.Fields(matches[1])
+	last := words[len(words)-1]
+	rangeRe := regexp.MustCompile(`^(\d+(?:\.\d{1,2})?)-(\d+(?:\.\d{1,2})?)$`)
+	boundRe := regexp.MustCompile(`^([<>])(\d+(?:\.\d{1,2})?)$`)
+	exactRe := regexp.MustCompile(`^\d+(?:\.\d{1,2})?$`)
+
+	switch {
+	case rangeRe.MatchString(last):
+		bounds := rangeRe.FindStringSubmatch(last)
+		low, _ := strconv.ParseFloat(bounds[1], 64)
+		high, _ := strconv.ParseFloat(bounds[2], 64)
+		if low > high {
+			low, high = high, low
+		}
+		minAmount, maxAmount = &low, &high
+	case boundRe.MatchString(last):
+		bound := boundRe.FindStringSubmatch(last)
+		value, _ := strconv.ParseFloat(bound[2], 64)
+		if bound[1] == ">" {
+			minAmount = &value
+		} else {
+			maxAmount = &value
+		}
+	case exactRe.MatchString(last):
+		value, _ := strconv.ParseFloat(last, 64)
+		minAmount, maxAmount = &value, &value
+	default:
+		return strings.Join(words, " "), nil, nil, nil
+	}
+
+	text = strings.Join(words[:len(words)-1], " ")
+	return
+}
+
+// SplitLeadDaysFromMessage strips an optional "notify [days] days before" suffix from a reminder
+// message, returning the rest of the message and the comma-separated lead days (empty if absent).
+func SplitLeadDaysFromMessage(message string) (rest string, leadDays string) {
+	re := regexp.MustCompile(`(?i)\s+notify\s+((?:\d+\s*,\s*)*\d+)\s+days?\s+before\s*$`)
+	loc := re.FindStringSubmatchIndex(message)
+	if loc == nil {
+		return message, ""
+	}
+
+	leadDays = strings.ReplaceAll(message[loc[2]:loc[3]], " ", "")
+	return message[:loc[0]], leadDays
+}
+
+// GetDefaultLeadDaysFromMessage parses "notify me [days] days before".
+func GetDefaultLeadDaysFromMessage(message string) (leadDays string, err error) {
+	re := regexp.MustCompile(`(?i)^\s*notify\s+me\s+((?:\d+\s*,\s*)*\d+)\s+days?\s+before\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: notify me [days] days before")
+		return
+	}
+
+	leadDays = strings.ReplaceAll(matches[1], " ", "")
+	return
+}
+
+// GetReminderTimeFromMessage parses "remind me at [time]" into a "15:04" time of day.
+func GetReminderTimeFromMessage(message string) (reminderTime string, err error) {
+	re := regexp.MustCompile(`(?i)^\s*remind\s+me\s+at\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: remind me at [time]")
+		return
+	}
 
-	date, err = time.Parse("01/02/2006", strings.TrimSpace(secondParts[1]))
+	hour, minute, err := ParseTimeOfDay(matches[1])
 	if err != nil {
-		err = fmt.Errorf("invalid date format, expected MM/DD/YYYY")
+		return
+	}
+	reminderTime = fmt.Sprintf("%02d:%02d", hour, minute)
+	return
+}
+
+// GetTimezoneFromMessage parses "timezone [IANA name]", e.g. "timezone Asia/Manila".
+func GetTimezoneFromMessage(message string) (timezone string, err error) {
+	re := regexp.MustCompile(`(?i)^\s*timezone\s+(\S+)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: timezone [name]")
 		return
 	}
 
+	timezone = matches[1]
 	return
 }
+
+var recurrenceUnits = map[string]string{
+	"day":   "DAILY",
+	"week":  "WEEKLY",
+	"month": "MONTHLY",
+	"year":  "YEARLY",
+}
+
+var recurrenceAdverbs = map[string]string{
+	"daily":    "DAILY",
+	"weekly":   "WEEKLY",
+	"monthly":  "MONTHLY",
+	"yearly":   "YEARLY",
+	"annually": "YEARLY",
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "1st": 1,
+	"second": 2, "2nd": 2,
+	"third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4,
+	"fifth": 5, "5th": 5,
+	"last": -1,
+}
+
+var weekdayWords = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// reminderDatePattern matches the "on [date] at [time]" part of a reminder, after which
+// any recurrence phrase follows.
+var reminderDatePattern = regexp.MustCompile(`(?i)\son\s+\d{1,2}/\d{1,2}(/\d{4})?(\s+at\s+\d{1,2}(:\d{2})?(\s*(am|pm))?)?`)
+
+// SplitRecurrenceFromMessage strips an optional recurrence phrase that follows the date of a
+// reminder message, such as "every month", "every 2 weeks", "every second friday",
+// "monthly on the last day" or "monthly until 12/2025", and returns the rest of the message
+// and the parsed rule. rule is nil for one-time reminders.
+func SplitRecurrenceFromMessage(message string) (rest string, rule *RecurrenceRule, err error) {
+	loc := reminderDatePattern.FindStringIndex(message)
+	if loc == nil {
+		return message, nil, nil
+	}
+
+	phrase := strings.TrimSpace(message[loc[1]:])
+	if phrase == "" {
+		return message, nil, nil
+	}
+
+	parsed, err := ParseRecurrencePhrase(phrase)
+	if err != nil {
+		return message, nil, err
+	}
+	return message[:loc[1]], &parsed, nil
+}
+
+// ParseRecurrencePhrase turns a plain-language schedule into a recurrence rule. It accepts a
+// base schedule followed by an optional "until [MM/YYYY or MM/DD/YYYY]" or "for [n] times".
+func ParseRecurrencePhrase(phrase string) (RecurrenceRule, error) {
+	phrase = strings.ToLower(strings.Join(strings.Fields(phrase), " "))
+	rule := RecurrenceRule{Interval: 1}
+
+	endRe := regexp.MustCompile(`\s+(?:until\s+(\d{2}/\d{2}/\d{4}|\d{1,2}/\d{4})|(?:for\s+)?(\d+)\s+times)$`)
+	if matches := endRe.FindStringSubmatch(phrase); matches != nil {
+		if matches[1] != "" {
+			var end time.Time
+			if day, parseErr := time.Parse("01/02/2006", matches[1]); parseErr == nil {
+				end = day.AddDate(0, 0, 1).Add(-time.Second)
+			} else if month, parseErr := time.Parse("1/2006", matches[1]); parseErr == nil {
+				end = month.AddDate(0, 1, 0).Add(-time.Second)
+			} else {
+				return rule, fmt.Errorf("invalid end date, expected MM/YYYY or MM/DD/YYYY")
+			}
+			rule.Until = &end
+		} else {
+			count, _ := strconv.Atoi(matches[2])
+			if count < 1 {
+				return rule, fmt.Errorf("invalid number of times")
+			}
+			rule.Count = count
+		}
+		phrase = strings.TrimSpace(phrase[:len(phrase)-len(matches[0])])
+	}
+
+	lastDayRe := regexp.MustCompile(`^(?:every|monthly)(?: month)? on the last day(?: of the month)?$|^every last day of the month$`)
+	nthWeekdayRe := regexp.MustCompile(`^(?:every|monthly on) (?:the )?(first|second|third|fourth|fifth|last|1st|2nd|3rd|4th|5th) (sunday|monday|tuesday|wednesday|thursday|friday|saturday)(?: of the month)?$`)
+	weekdayRe := regexp.MustCompile(`^every (sunday|monday|tuesday|wednesday|thursday|friday|saturday)$`)
+	intervalRe := regexp.MustCompile(`^every (\d+|other) (day|week|month|year)s?$`)
+	unitRe := regexp.MustCompile(`^every (day|week|month|year)$`)
+
+	switch {
+	case lastDayRe.MatchString(phrase):
+		rule.Freq = "MONTHLY"
+		rule.ByMonthDay = []int{-1}
+	case nthWeekdayRe.MatchString(phrase):
+		matches := nthWeekdayRe.FindStringSubmatch(phrase)
+		rule.Freq = "MONTHLY"
+		rule.ByDay = []WeekdayNum{{Ordinal: ordinalWords[matches[1]], Weekday: weekdayWords[matches[2]]}}
+	case weekdayRe.MatchString(phrase):
+		matches := weekdayRe.FindStringSubmatch(phrase)
+		rule.Freq = "WEEKLY"
+		rule.ByDay = []WeekdayNum{{Weekday: weekdayWords[matches[1]]}}
+	case intervalRe.MatchString(phrase):
+		matches := intervalRe.FindStringSubmatch(phrase)
+		rule.Freq = recurrenceUnits[matches[2]]
+		if matches[1] == "other" {
+			rule.Interval = 2
+		} else {
+			interval, _ := strconv.Atoi(matches[1])
+			if interval < 1 {
+				return rule, fmt.Errorf("invalid interval")
+			}
+			rule.Interval = interval
+		}
+	case unitRe.MatchString(phrase):
+		rule.Freq = recurrenceUnits[unitRe.FindStringSubmatch(phrase)[1]]
+	case recurrenceAdverbs[phrase] != "":
+		rule.Freq = recurrenceAdverbs[phrase]
+	default:
+		return rule, fmt.Errorf("unrecognized schedule: %s", phrase)
+	}
+
+	return rule, nil
+}
+
+// GetPayCommandDataFromMessage parses "pay [payee] [amount] on [MM/DD/YYYY] at [time]". The
+// amount, the year and the time are optional; amount is nil when left out.
+func GetPayCommandDataFromMessage(message string) (name string, amount *float64, date time.Time, hasTime bool, err error) {
+	re := regexp.MustCompile(`(?i)^\s*pay\s+(.+?)(?:\s+(\d+(?:\.\d{1,2})?))?\s+on\s+(\d{1,2}/\d{1,2}(?:/\d{4})?(?:\s+at\s+.+?)?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: pay [payee] [amount] on [month/day]")
+		return
+	}
+
+	name = strings.TrimSpace(matches[1])
+	if matches[2] != "" {
+		value, parseErr := strconv.ParseFloat(matches[2], 64)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid amount format")
+			return
+		}
+		amount = &value
+	}
+
+	date, hasTime, err = ParseReminderDueDate(matches[3])
+	return
+}
+
+// PayeeInput is what an "add payee" or "edit payee" message describes.
+type PayeeInput struct {
+	Name               string
+	Payment            *PaymentDetails // nil when no payment details were given
+	DefaultAmount      *float64
+	ClearDefaultAmount bool // "default none"
+}
+
+var payeeMessagePattern = regexp.MustCompile(`(?i)^\s*(?:add|edit)\s+payee\s+(.+?)(?:\s+default\s+(none|\d+(?:\.\d{1,2})?))?\s*$`)
+
+// GetPayeeDataFromMessage parses "add payee [name] via [method] [account] default [amount]",
+// the GCash shorthand "add payee [name]:[number]", and the same forms with "edit payee".
+// The default amount is optional, and "default none" clears it when editing.
+func GetPayeeDataFromMessage(message string) (input PayeeInput, err error) {
+	matches := payeeMessagePattern.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: add payee [name] via [payment method] [account]")
+		return
+	}
+
+	var amount float64
+	switch strings.ToLower(matches[2]) {
+	case "":
+	case "none":
+		input.ClearDefaultAmount = true
+	default:
+		amount, err = strconv.ParseFloat(matches[2], 64)
+		if err != nil {
+			err = fmt.Errorf("invalid default amount")
+			return
+		}
+		input.DefaultAmount = &amount
+	}
+
+	nameAndPayment := matches[1]
+	if via := reminderViaPattern.FindStringIndex(nameAndPayment); via != nil {
+		input.Name = strings.TrimSpace(nameAndPayment[:via[0]])
+		var payment PaymentDetails
+		payment, err = ParsePaymentMethod(nameAndPayment[via[1]:], amount)
+		if err != nil {
+			return
+		}
+		input.Payment = &payment
+	} else if nameAndNumber := strings.Split(nameAndPayment, ":"); len(nameAndNumber) == 2 {
+		input.Name = strings.TrimSpace(nameAndNumber[0])
+		input.Payment = &PaymentDetails{Method: "Gcash", AccountNumber: strings.TrimSpace(nameAndNumber[1])}
+	} else {
+		input.Name = strings.TrimSpace(nameAndPayment)
+	}
+
+	if input.Name == "" {
+		err = fmt.Errorf("payee name is missing")
+	}
+	return
+}
+
+// GetRenamePayeeDataFromMessage parses "rename payee [name] to [new name]".
+func GetRenamePayeeDataFromMessage(message string) (name string, newName string, err error) {
+	re := regexp.MustCompile(`(?i)^\s*rename\s+payee\s+(.+?)\s+to\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: rename payee [name] to [new name]")
+		return
+	}
+	return matches[1], matches[2], nil
+}
+
+// GetRemovePayeeNameFromMessage parses "remove payee [name]".
+func GetRemovePayeeNameFromMessage(message string) (string, error) {
+	re := regexp.MustCompile(`(?i)^\s*(?:remove|delete)\s+payee\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		return "", fmt.Errorf("invalid format, expected: remove payee [name]")
+	}
+	return matches[1], nil
+}
+
+// GetHouseholdNameFromMessage parses "create household [name]".
+func GetHouseholdNameFromMessage(message string) (string, error) {
+	re := regexp.MustCompile(`(?i)^\s*create\s+household\s+(.+?)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		return "", fmt.Errorf("invalid format, expected: create household [name]")
+	}
+	return matches[1], nil
+}
+
+// GetInviteCodeFromMessage parses "join household [code]".
+func GetInviteCodeFromMessage(message string) (string, error) {
+	re := regexp.MustCompile(`(?i)^\s*join\s+household\s+([a-z0-9]+)\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		return "", fmt.Errorf("invalid format, expected: join household [code]")
+	}
+	return strings.ToUpper(matches[1]), nil
+}
+
+// GetHouseholdReportRangeFromMessage parses "household report [day|week|month]", defaulting to month.
+func GetHouseholdReportRangeFromMessage(message string) string {
+	re := regexp.MustCompile(`(?i)^\s*household\s+report\s+(day|week|month)\s*$`)
+	if matches := re.FindStringSubmatch(message); matches != nil {
+		return strings.ToLower(matches[1])
+	}
+	return "month"
+}
+
+var recurringExpensePattern = regexp.MustCompile(`(?i)^\s*recurring\s+(\d+(?:\.\d{1,2})?)\s+for\s+(.+?)\s+on\s+(\d{1,2})/(\d{1,2})(?:/(\d{4}))?\s*$`)
+
+// GetRecurringExpenseDataFromMessage parses "recurring [amount] for [item] on [MM/DD] [schedule]",
+// e.g. "recurring 15000 for rent on 06/01 every month". Without a year the date falls in the
+// current year, even if it has passed; the schedule decides the next date to log.
+func GetRecurringExpenseDataFromMessage(message string) (amount float64, category string, start time.Time, rule RecurrenceRule, err error) {
+	rest, parsedRule, err := SplitRecurrenceFromMessage(message)
+	if err != nil {
+		return
+	}
+	if parsedRule == nil {
+		err = fmt.Errorf("add a schedule such as \"every month\"")
+		return
+	}
+	rule = *parsedRule
+
+	matches := recurringExpensePattern.FindStringSubmatch(rest)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: recurring [amount] for [item] on [month/day] every [schedule]")
+		return
+	}
+
+	amount, err = strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+		return
+	}
+	category = strings.TrimSpace(matches[2])
+
+	dateText := fmt.Sprintf("%s/%s/%d", matches[3], matches[4], time.Now().Year())
+	if matches[5] != "" {
+		dateText = fmt.Sprintf("%s/%s/%s", matches[3], matches[4], matches[5])
+	}
+	start, err = time.Parse("1/2/2006", dateText)
+	if err != nil {
+		err = fmt.Errorf("invalid date format, expected MM/DD or MM/DD/YYYY")
+	}
+	return
+}
+
+// GetExportRangeFromMessage parses "export [day|week|month]", defaulting to month.
+func GetExportRangeFromMessage(message string) string {
+	re := regexp.MustCompile(`(?i)^\s*export\s+(day|week|month)\s*$`)
+	if matches := re.FindStringSubmatch(message); matches != nil {
+		return strings.ToLower(matches[1])
+	}
+	return "month"
+}
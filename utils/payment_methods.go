@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"fmt"
+	"quickyexpensetracker/models"
+	"quickyexpensetracker/templates"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Transfer rails for bank payments. InstaPay is real-time with a per-transfer cap; PESONet is
+// batched and settles the same or next banking day.
+const (
+	RailInstaPay = "InstaPay"
+	RailPESONet  = "PESONet"
+)
+
+// InstaPayLimit is the largest amount a single InstaPay transfer can carry.
+const InstaPayLimit = 50000.0
+
+// PaymentMethod describes how a reminder is paid: which recipient details it needs, how they
+// are validated and what the reminder card offers for paying.
+type PaymentMethod struct {
+	Key             string // Stored in RemindersLog.PaymentMethod
+	Name            string // Display name
+	AccountLabel    string // What the account number is called; empty when the method has none
+	RequiresAccount bool
+	AccountPattern  *regexp.Regexp
+	AcquirerBIC     string // QR Ph acquirer; empty when payments can't be pre-filled with a QR code
+	PayButtonTitle  string // Card button that shows how to pay; empty for none
+}
+
+var mobileNumberPattern = regexp.MustCompile(`^09\d{9}$`)
+var bankAccountPattern = regexp.MustCompile(`^\d{10,16}$`)
+var cardDigitsPattern = regexp.MustCompile(`^\d{4}$`)
+var digitsPattern = regexp.MustCompile(`^\d+$`)
+
+// PaymentMethods is the registry of supported payment methods, keyed by PaymentMethod.Key.
+var PaymentMethods = map[string]PaymentMethod{
+	"Gcash": {
+		Key: "Gcash", Name: "GCash", AccountLabel: "GCash number", RequiresAccount: true,
+		AccountPattern: mobileNumberPattern, AcquirerBIC: GcashBIC, PayButtonTitle: "Pay with GCash",
+	},
+	"Maya": {
+		Key: "Maya", Name: "Maya", AccountLabel: "Maya number", RequiresAccount: true,
+		AccountPattern: mobileNumberPattern, AcquirerBIC: MayaBIC, PayButtonTitle: "Pay with Maya",
+	},
+	"Bank": {
+		Key: "Bank", Name: "Bank transfer", AccountLabel: "Account number", RequiresAccount: true,
+		AccountPattern: bankAccountPattern, PayButtonTitle: "Transfer Details",
+	},
+	"Cash": {
+		Key: "Cash", Name: "Cash",
+	},
+	"Card": {
+		Key: "Card", Name: "Card", AccountLabel: "Card ending", AccountPattern: cardDigitsPattern,
+	},
+}
+
+// Bank describes a bank that can receive InstaPay and PESONet transfers.
+type Bank struct {
+	Name string
+	BIC  string
+}
+
+// Banks maps the names users type after "via" to banks.
+var Banks = map[string]Bank{
+	"bdo":           {Name: "BDO", BIC: "BNORPHMMXXX"},
+	"bpi":           {Name: "BPI", BIC: "BOPIPHMMXXX"},
+	"metrobank":     {Name: "Metrobank", BIC: "MBTCPHMMXXX"},
+	"landbank":      {Name: "Landbank", BIC: "TLBPPHMMXXX"},
+	"unionbank":     {Name: "UnionBank", BIC: "UBPHPHMMXXX"},
+	"pnb":           {Name: "PNB", BIC: "PNBMPHMMXXX"},
+	"rcbc":          {Name: "RCBC", BIC: "RCBCPHMMXXX"},
+	"security bank": {Name: "Security Bank", BIC: "SETCPHMMXXX"},
+	"chinabank":     {Name: "Chinabank", BIC: "CHBKPHMMXXX"},
+	"eastwest":      {Name: "EastWest", BIC: "EWBCPHMMXXX"},
+}
+
+// paymentMethodAliases maps the words users type after "via" to payment method keys.
+var paymentMethodAliases = map[string]string{
+	"gcash":   "Gcash",
+	"maya":    "Maya",
+	"paymaya": "Maya",
+	"bank":    "Bank",
+	"cash":    "Cash",
+	"card":    "Card",
+	"credit":  "Card",
+	"debit":   "Card",
+}
+
+// GetPaymentMethod looks up a payment method by key, defaulting to GCash for reminders saved
+// before other methods existed.
+func GetPaymentMethod(key string) PaymentMethod {
+	if method, ok := PaymentMethods[key]; ok {
+		return method
+	}
+	return PaymentMethods["Gcash"]
+}
+
+// PaymentDetails are the recipient details of a reminder payment.
+type PaymentDetails struct {
+	Method        string // PaymentMethod.Key
+	AccountNumber string
+	BankName      string // Bank transfers only
+	TransferRail  string // RailInstaPay or RailPESONet, bank transfers only
+}
+
+// ValidatePaymentDetails checks the recipient details against what the payment method needs.
+// InstaPay transfers above InstaPayLimit are rejected so PESONet is chosen instead.
+func ValidatePaymentDetails(details PaymentDetails, amount float64) error {
+	method, ok := PaymentMethods[details.Method]
+	if !ok {
+		return fmt.Errorf("unknown payment method %s", details.Method)
+	}
+	if details.AccountNumber == "" {
+		if method.RequiresAccount {
+			return fmt.Errorf("%s payments need a %s", method.Name, strings.ToLower(method.AccountLabel))
+		}
+	} else if method.AccountPattern == nil {
+		return fmt.Errorf("%s payments don't take an account number", method.Name)
+	} else if !method.AccountPattern.MatchString(details.AccountNumber) {
+		return fmt.Errorf("%s is not a valid %s", details.AccountNumber, strings.ToLower(method.AccountLabel))
+	}
+
+	if method.Key == "Bank" {
+		if details.BankName == "" {
+			return fmt.Errorf("bank transfers need a bank name")
+		}
+		if details.TransferRail != RailInstaPay && details.TransferRail != RailPESONet {
+			return fmt.Errorf("transfer rail must be %s or %s", RailInstaPay, RailPESONet)
+		}
+		if details.TransferRail == RailInstaPay && amount > InstaPayLimit {
+			return fmt.Errorf("InstaPay transfers are limited to ₱%.2f; use PESONet for larger amounts", InstaPayLimit)
+		}
+	}
+	return nil
+}
+
+// ParsePaymentMethod parses what follows "via" in a reminder, such as "gcash 09171234567",
+// "maya 09171234567", "bdo 0012345678", "bpi pesonet 0012345678", "cash" or "card 1234".
+// Bank transfers use InstaPay unless PESONet is named or the amount is over the InstaPay limit.
+func ParsePaymentMethod(text string, amount float64) (PaymentDetails, error) {
+	words := strings.Fields(strings.ToLower(text))
+	var details PaymentDetails
+	if len(words) == 0 {
+		return details, fmt.Errorf("missing payment method after 'via'")
+	}
+
+	if last := words[len(words)-1]; digitsPattern.MatchString(last) {
+		details.AccountNumber = last
+		words = words[:len(words)-1]
+	}
+	for i, word := range words {
+		switch word {
+		case "instapay":
+			details.TransferRail = RailInstaPay
+		case "pesonet":
+			details.TransferRail = RailPESONet
+		default:
+			continue
+		}
+		words = append(words[:i:i], words[i+1:]...)
+		break
+	}
+
+	name := strings.Join(words, " ")
+	if key, ok := paymentMethodAliases[name]; ok {
+		details.Method = key
+	} else if bank, ok := Banks[strings.TrimSuffix(name, " bank")]; ok {
+		details.Method = "Bank"
+		details.BankName = bank.Name
+	} else if bank, ok := Banks[name]; ok {
+		details.Method = "Bank"
+		details.BankName = bank.Name
+	} else {
+		return details, fmt.Errorf("unknown payment method %q; use gcash, maya, cash, card or a bank such as %s", name, strings.Join(BankNames(), ", "))
+	}
+
+	if details.Method == "Bank" && details.TransferRail == "" {
+		details.TransferRail = RailInstaPay
+		if amount > InstaPayLimit {
+			details.TransferRail = RailPESONet
+		}
+	}
+	if details.Method != "Bank" && details.TransferRail != "" {
+		return details, fmt.Errorf("%s is only for bank transfers", details.TransferRail)
+	}
+
+	return details, ValidatePaymentDetails(details, amount)
+}
+
+// BankNames lists the banks that can be named after "via", in alphabetical order.
+func BankNames() []string {
+	var names []string
+	for alias := range Banks {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BankBIC returns the QR Ph acquirer code of a bank by display name.
+func BankBIC(bankName string) string {
+	for _, bank := range Banks {
+		if strings.EqualFold(bank.Name, bankName) {
+			return bank.BIC
+		}
+	}
+	return ""
+}
+
+// DescribePaymentAccount renders a reminder's recipient details for cards and messages,
+// e.g. "GCash: 09171234567", "BDO via InstaPay: 0012345678" or "Cash".
+func DescribePaymentAccount(reminder models.RemindersLog) string {
+	return DescribePaymentDetails(PaymentDetails{
+		Method:        reminder.PaymentMethod,
+		AccountNumber: reminder.AccountNumber,
+		BankName:      reminder.BankName,
+		TransferRail:  reminder.TransferRail,
+	})
+}
+
+// DescribePaymentDetails renders recipient details the same way as DescribePaymentAccount.
+func DescribePaymentDetails(details PaymentDetails) string {
+	method := GetPaymentMethod(details.Method)
+	switch {
+	case method.Key == "Bank":
+		return fmt.Sprintf("%s via %s: %s", details.BankName, details.TransferRail, details.AccountNumber)
+	case details.AccountNumber == "":
+		return method.Name
+	case method.Key == "Card":
+		return fmt.Sprintf("Card ending %s", details.AccountNumber)
+	default:
+		return fmt.Sprintf("%s: %s", method.Name, details.AccountNumber)
+	}
+}
+
+// PaymentQR returns the QR Ph details for paying a reminder, if its method supports it.
+// PESONet transfers can't be started from a QR code.
+func PaymentQR(reminder models.RemindersLog) (QRPhPayment, bool) {
+	method := GetPaymentMethod(reminder.PaymentMethod)
+	bic := method.AcquirerBIC
+	if method.Key == "Bank" && reminder.TransferRail == RailInstaPay {
+		bic = BankBIC(reminder.BankName)
+	}
+	if bic == "" || reminder.AccountNumber == "" {
+		return QRPhPayment{}, false
+	}
+	return QRPhPayment{
+		AcquirerBIC:   bic,
+		AccountNumber: reminder.AccountNumber,
+		Name:          reminder.Recipient,
+		Amount:        reminder.Amount,
+	}, true
+}
+
+// PayButton returns the card button that shows how to pay a reminder, if its method has one.
+func PayButton(reminder models.RemindersLog) (templates.Button, bool) {
+	method := GetPaymentMethod(reminder.PaymentMethod)
+	if method.PayButtonTitle == "" {
+		return templates.Button{}, false
+	}
+	return templates.Button{
+		Type:    "postback",
+		Title:   method.PayButtonTitle,
+		Payload: "PAY_REMINDER_" + fmt.Sprint(reminder.ID),
+	}, true
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePaymentMethod(t *testing.T) {
+	tests := []struct {
+		text    string
+		amount  float64
+		want    PaymentDetails
+		wantErr string
+	}{
+		{text: "gcash 09171234567", amount: 500, want: PaymentDetails{Method: "Gcash", AccountNumber: "09171234567"}},
+		{text: "GCASH 09171234567", amount: 500, want: PaymentDetails{Method: "Gcash", AccountNumber: "09171234567"}},
+		{text: "maya 09171234567", amount: 500, want: PaymentDetails{Method: "Maya", AccountNumber: "09171234567"}},
+		{text: "paymaya 09171234567", amount: 500, want: PaymentDetails{Method: "Maya", AccountNumber: "09171234567"}},
+		{text: "cash", amount: 500, want: PaymentDetails{Method: "Cash"}},
+		{text: "card 1234", amount: 500, want: PaymentDetails{Method: "Card", AccountNumber: "1234"}},
+		{text: "credit 1234", amount: 500, want: PaymentDetails{Method: "Card", AccountNumber: "1234"}},
+		{text: "debit", amount: 500, want: PaymentDetails{Method: "Card"}},
+		{text: "bdo 0012345678", amount: 1000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BDO", TransferRail: RailInstaPay}},
+		{text: "bdo bank 0012345678", amount: 1000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BDO", TransferRail: RailInstaPay}},
+		{text: "Security Bank 0012345678", amount: 1000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "Security Bank", TransferRail: RailInstaPay}},
+		{text: "bpi pesonet 0012345678", amount: 1000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BPI", TransferRail: RailPESONet}},
+		{text: "instapay unionbank 0012345678", amount: 1000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "UnionBank", TransferRail: RailInstaPay}},
+		{text: "metrobank 0012345678", amount: 60000, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "Metrobank", TransferRail: RailPESONet}},
+		{text: "metrobank 0012345678", amount: InstaPayLimit, want: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "Metrobank", TransferRail: RailInstaPay}},
+		{text: "bdo instapay 0012345678", amount: 60000, wantErr: "InstaPay transfers are limited"},
+		{text: "gcash instapay 09171234567", amount: 500, wantErr: "only for bank transfers"},
+		{text: "cash pesonet", amount: 500, wantErr: "only for bank transfers"},
+		{text: "gcash", amount: 500, wantErr: "need a gcash number"},
+		{text: "bdo", amount: 500, wantErr: "Bank transfer payments need"},
+		{text: "gcash 12345", amount: 500, wantErr: "not a valid gcash number"},
+		{text: "maya 08171234567", amount: 500, wantErr: "not a valid maya number"},
+		{text: "bdo 12345", amount: 500, wantErr: "not a valid account number"},
+		{text: "card 12345", amount: 500, wantErr: "not a valid card ending"},
+		{text: "cash 1234", amount: 500, wantErr: "don't take an account number"},
+		{text: "bank 0012345678", amount: 500, wantErr: "need a bank name"},
+		{text: "venmo 09171234567", amount: 500, wantErr: "unknown payment method"},
+		{text: "  ", amount: 500, wantErr: "missing payment method"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePaymentMethod(tt.text, tt.amount)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePaymentMethod(%q, %v) error = %v, want one containing %q", tt.text, tt.amount, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePaymentMethod(%q, %v) returned error: %v", tt.text, tt.amount, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePaymentMethod(%q, %v) = %+v, want %+v", tt.text, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePaymentDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		details PaymentDetails
+		amount  float64
+		wantErr string
+	}{
+		{name: "gcash", details: PaymentDetails{Method: "Gcash", AccountNumber: "09171234567"}, amount: 500},
+		{name: "pesonet above the instapay limit", details: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BDO", TransferRail: RailPESONet}, amount: 100000},
+		{name: "unknown method", details: PaymentDetails{Method: "Venmo"}, amount: 500, wantErr: "unknown payment method"},
+		{name: "bank without a rail", details: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BDO"}, amount: 500, wantErr: "transfer rail must be"},
+		{name: "instapay above the limit", details: PaymentDetails{Method: "Bank", AccountNumber: "0012345678", BankName: "BDO", TransferRail: RailInstaPay}, amount: 50000.01, wantErr: "InstaPay transfers are limited"},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePaymentDetails(tt.details, tt.amount)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: returned error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
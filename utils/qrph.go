@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Acquirer codes the e-wallets use in QR Ph person-to-person payloads.
+const (
+	GcashBIC = "GXCHPHM2XXX"
+	MayaBIC  = "PAPHPHM1XXX"
+)
+
+// qrPhP2PGUID identifies the QR Ph person-to-person scheme in the merchant account template.
+const qrPhP2PGUID = "com.p2pqrpay"
+
+// qrPhMaxNameLength is the EMVCo limit for the merchant (recipient) name.
+const qrPhMaxNameLength = 25
+
+// QRPhPayment holds what a QR Ph code needs to pre-fill a transfer to a recipient.
+type QRPhPayment struct {
+	AcquirerBIC   string  // Bank or e-wallet of the recipient, e.g. GcashBIC
+	AccountNumber string  // Recipient account; for e-wallets the 09XXXXXXXXX mobile number
+	Name          string  // Recipient name shown in the paying app
+	City          string  // Defaults to Manila
+	Amount        float64 // Zero leaves the amount for the payer to enter
+}
+
+// BuildQRPhPayload encodes a payment as an EMVCo merchant-presented QR payload following the
+// QR Ph person-to-person template. A payload with an amount is marked dynamic (single use).
+func BuildQRPhPayload(payment QRPhPayment) (string, error) {
+	if payment.AcquirerBIC == "" || payment.AccountNumber == "" {
+		return "", fmt.Errorf("acquirer and account number are required")
+	}
+	if payment.Amount < 0 {
+		return "", fmt.Errorf("amount cannot be negative")
+	}
+
+	name := asciiName(payment.Name)
+	if name == "" {
+		name = "NA"
+	}
+	if len(name) > qrPhMaxNameLength {
+		name = strings.TrimSpace(name[:qrPhMaxNameLength])
+	}
+	city := payment.City
+	if city == "" {
+		city = "Manila"
+	}
+
+	initiation := "11" // Static: can be paid more than once
+	if payment.Amount > 0 {
+		initiation = "12"
+	}
+
+	accountInfo := emvField("00", qrPhP2PGUID) +
+		emvField("01", payment.AcquirerBIC) +
+		emvField("02", "99964403") +
+		emvField("04", payment.AccountNumber)
+	if mobile, ok := internationalMobileNumber(payment.AccountNumber); ok {
+		accountInfo += emvField("05", mobile)
+	}
+
+	payload := emvField("00", "01") +
+		emvField("01", initiation) +
+		emvField("27", accountInfo) +
+		emvField("52", "6016") +
+		emvField("53", "608")
+	if payment.Amount > 0 {
+		payload += emvField("54", strconv.FormatFloat(payment.Amount, 'f', 2, 64))
+	}
+	payload += emvField("58", "PH") +
+		emvField("59", name) +
+		emvField("60", city)
+
+	// The checksum covers everything up to and including its own ID and length
+	payload += "6304"
+	return payload + fmt.Sprintf("%04X", CRC16CCITT([]byte(payload))), nil
+}
+
+// emvField encodes one EMVCo data object as ID, two-digit length and value.
+// asciiName transliterates a recipient name to ASCII, e.g. "Peña" to "Pena", since paying apps
+// expect the name field in plain ASCII and it is truncated by byte length. Characters without
+// an ASCII form are dropped.
+func asciiName(name string) string {
+	var ascii strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if r < utf8.RuneSelf {
+			ascii.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(ascii.String()), " ")
+}
+
+func emvField(id string, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// internationalMobileNumber formats a 09XXXXXXXXX number as +63-9XX-XXXXXXX.
+func internationalMobileNumber(number string) (string, bool) {
+	if !IsGcashNumberCorrect(number) {
+		return "", false
+	}
+	number = strings.TrimSpace(number)
+	return fmt.Sprintf("+63-%s-%s", number[1:4], number[4:]), true
+}
+
+// CRC16CCITT computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021, initial 0xFFFF)
+// that EMVCo QR payloads end with.
+func CRC16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// RenderQRCodePNG draws a payload as a square PNG image of the given size in pixels.
+func RenderQRCodePNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
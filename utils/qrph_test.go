@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCRC16CCITT(t *testing.T) {
+	// Standard check value for CRC-16/CCITT-FALSE
+	if got := CRC16CCITT([]byte("123456789")); got != 0x29B1 {
+		t.Fatalf("CRC16CCITT(123456789) = %04X, want 29B1", got)
+	}
+}
+
+func TestBuildQRPhPayload(t *testing.T) {
+	payload, err := BuildQRPhPayload(QRPhPayment{
+		AcquirerBIC:   GcashBIC,
+		AccountNumber: "09171234567",
+		Name:          "Mark",
+		Amount:        1500,
+	})
+	if err != nil {
+		t.Fatalf("BuildQRPhPayload returned error: %v", err)
+	}
+
+	want := "000201" +
+		"010212" +
+		"2777" +
+		"0012com.p2pqrpay" +
+		"0111GXCHPHM2XXX" +
+		"020899964403" +
+		"041109171234567" +
+		"0515+63-917-1234567" +
+		"52046016" +
+		"5303608" +
+		"54071500.00" +
+		"5802PH" +
+		"5904Mark" +
+		"6006Manila" +
+		"6304"
+	if !strings.HasPrefix(payload, want) {
+		t.Fatalf("payload = %s\nwant prefix %s", payload, want)
+	}
+	if len(payload) != len(want)+4 {
+		t.Fatalf("payload length = %d, want %d", len(payload), len(want)+4)
+	}
+
+	body, checksum := payload[:len(payload)-4], payload[len(payload)-4:]
+	crc := CRC16CCITT([]byte(body))
+	if want := fmt.Sprintf("%04X", crc); checksum != want {
+		t.Fatalf("checksum = %s, want %s", checksum, want)
+	}
+}
+
+func TestBuildQRPhPayloadWithoutAmountIsStatic(t *testing.T) {
+	payload, err := BuildQRPhPayload(QRPhPayment{AcquirerBIC: GcashBIC, AccountNumber: "09171234567", Name: "Mark"})
+	if err != nil {
+		t.Fatalf("BuildQRPhPayload returned error: %v", err)
+	}
+	if !strings.HasPrefix(payload, "000201010211") {
+		t.Fatalf("payload %s should be static (010211)", payload)
+	}
+	if strings.Contains(payload, "5303608"+"54") {
+		t.Fatalf("payload %s should not carry an amount", payload)
+	}
+}
+
+func TestBuildQRPhPayloadTruncatesLongNames(t *testing.T) {
+	payload, err := BuildQRPhPayload(QRPhPayment{
+		AcquirerBIC:   GcashBIC,
+		AccountNumber: "09171234567",
+		Name:          "Juan Dela Cruz Santos Reyes Jr",
+		Amount:        99.5,
+	})
+	if err != nil {
+		t.Fatalf("BuildQRPhPayload returned error: %v", err)
+	}
+	if !strings.Contains(payload, "5925Juan Dela Cruz Santos Rey6006") {
+		t.Fatalf("payload %s should carry the name truncated to 25 characters", payload)
+	}
+	if !strings.Contains(payload, "540599.50") {
+		t.Fatalf("payload %s should format the amount with two decimals", payload)
+	}
+}
+
+func TestBuildQRPhPayloadTransliteratesNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // Field 59 as it should appear in the payload
+	}{
+		{name: "Peña", want: "5904Pena"},
+		{name: "José Mari Chan Dela Peña Jr", want: "5924Jose Mari Chan Dela Pena"},
+		{name: "Ma. Cristina Ñiguez-Santos", want: "5925Ma. Cristina Niguez-Santo"},
+		{name: "陳", want: "5902NA"},
+	}
+
+	for _, tt := range tests {
+		payload, err := BuildQRPhPayload(QRPhPayment{AcquirerBIC: GcashBIC, AccountNumber: "09171234567", Name: tt.name})
+		if err != nil {
+			t.Errorf("BuildQRPhPayload(%q) returned error: %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(payload, tt.want+"6006Manila") {
+			t.Errorf("BuildQRPhPayload(%q) = %s, want name field %s", tt.name, payload, tt.want)
+		}
+		if !utf8.ValidString(payload) || len(payload) != len([]rune(payload)) {
+			t.Errorf("BuildQRPhPayload(%q) = %q, want an ASCII payload", tt.name, payload)
+		}
+	}
+}
+
+func TestBuildQRPhPayloadRequiresAccount(t *testing.T) {
+	if _, err := BuildQRPhPayload(QRPhPayment{AcquirerBIC: GcashBIC}); err == nil {
+		t.Fatal("expected an error without an account number")
+	}
+}
+
+func TestRenderQRCodePNG(t *testing.T) {
+	png, err := RenderQRCodePNG("000201010211", 256)
+	if err != nil {
+		t.Fatalf("RenderQRCodePNG returned error: %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatal("RenderQRCodePNG did not return a PNG image")
+	}
+}
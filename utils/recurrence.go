@@ -0,0 +1,572 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceRule is the subset of an RFC 5545 RRULE used for reminders: FREQ, INTERVAL,
+// BYMONTH, BYMONTHDAY, BYDAY (with optional ordinals), BYSETPOS, COUNT and UNTIL.
+//
+// Occurrences are generated from a start time (the DTSTART), which also supplies the time of
+// day. One deliberate difference from RFC 5545: when a MONTHLY or YEARLY rule has no BYDAY or
+// BYMONTHDAY, the start's day of the month is clamped to the month's length instead of
+// skipping short months, so a bill due on the 31st falls on the 30th or 28th/29th.
+type RecurrenceRule struct {
+	Freq       string // DAILY, WEEKLY, MONTHLY or YEARLY
+	Interval   int
+	ByMonth    []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	BySetPos   []int // Picks occurrences by position within each period, e.g. -1 for the last
+	Count      int
+	Until      *time.Time
+}
+
+// WeekdayNum is a BYDAY entry such as "FR" (every Friday) or "2FR" (the second Friday).
+// Ordinal is 0 when absent and negative when counting from the end of the month.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxRecurrencePeriods bounds how far Next searches, so a rule that can never match
+// (e.g. BYMONTHDAY=31 with BYMONTH=2) does not loop forever.
+const maxRecurrencePeriods = 5000
+
+// ParseRRule parses an RRULE value such as "FREQ=MONTHLY;BYDAY=2FR;COUNT=12".
+// A leading "RRULE:" is accepted. UNTIL may be a date (YYYYMMDD) or a date-time
+// (YYYYMMDDTHHMMSS, optionally with a trailing Z) and is read as wall-clock time.
+func ParseRRule(value string) (RecurrenceRule, error) {
+	rule := RecurrenceRule{Interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return rule, fmt.Errorf("invalid rule part: %s", part)
+		}
+		key, val := strings.ToUpper(keyValue[0]), strings.ToUpper(keyValue[1])
+
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = val
+			default:
+				return rule, fmt.Errorf("unsupported frequency: %s", val)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return rule, fmt.Errorf("invalid interval: %s", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return rule, fmt.Errorf("invalid count: %s", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(val)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = &until
+		case "BYMONTH":
+			months, err := parseIntList(val, 1, 12)
+			if err != nil {
+				return rule, fmt.Errorf("invalid BYMONTH: %w", err)
+			}
+			rule.ByMonth = months
+		case "BYMONTHDAY":
+			days, err := parseIntList(val, -31, 31)
+			if err != nil {
+				return rule, fmt.Errorf("invalid BYMONTHDAY: %w", err)
+			}
+			for _, day := range days {
+				if day == 0 {
+					return rule, fmt.Errorf("invalid BYMONTHDAY: 0")
+				}
+			}
+			rule.ByMonthDay = days
+		case "BYSETPOS":
+			positions, err := parseIntList(val, -366, 366)
+			if err != nil {
+				return rule, fmt.Errorf("invalid BYSETPOS: %w", err)
+			}
+			for _, position := range positions {
+				if position == 0 {
+					return rule, fmt.Errorf("invalid BYSETPOS: 0")
+				}
+			}
+			rule.BySetPos = positions
+		case "BYDAY":
+			for _, entry := range strings.Split(val, ",") {
+				weekdayNum, err := parseWeekdayNum(entry)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, weekdayNum)
+			}
+		default:
+			return rule, fmt.Errorf("unsupported rule part: %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("rule is missing FREQ")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return rule, fmt.Errorf("rule cannot have both COUNT and UNTIL")
+	}
+	// As in RFC 5545, weekday ordinals only apply within a month or year, and a week has no
+	// days of the month to pick from.
+	if rule.Freq == "DAILY" || rule.Freq == "WEEKLY" {
+		for _, weekdayNum := range rule.ByDay {
+			if weekdayNum.Ordinal != 0 {
+				return rule, fmt.Errorf("BYDAY ordinals need a MONTHLY or YEARLY rule")
+			}
+		}
+	}
+	if rule.Freq == "WEEKLY" && len(rule.ByMonthDay) > 0 {
+		return rule, fmt.Errorf("BYMONTHDAY cannot be used with a WEEKLY rule")
+	}
+	if len(rule.BySetPos) > 0 && len(rule.ByMonth) == 0 && len(rule.ByMonthDay) == 0 && len(rule.ByDay) == 0 {
+		return rule, fmt.Errorf("BYSETPOS needs another BY rule part")
+	}
+	return rule, nil
+}
+
+// String renders the rule in RRULE form, without the "RRULE:" prefix.
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (w WeekdayNum) String() string {
+	code := ""
+	for c, weekday := range weekdayCodes {
+		if weekday == w.Weekday {
+			code = c
+		}
+	}
+	if w.Ordinal == 0 {
+		return code
+	}
+	return strconv.Itoa(w.Ordinal) + code
+}
+
+// Next returns the first occurrence of the rule strictly after `after`, for a series
+// starting at start. ok is false when the series has ended (COUNT or UNTIL reached).
+// The start itself is always the first occurrence.
+func (r RecurrenceRule) Next(start time.Time, after time.Time) (next time.Time, ok bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	seen := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		candidates := r.expandPeriod(start, period*interval)
+		if period == 0 {
+			// DTSTART always counts as the first occurrence, even if it does not match the rule.
+			candidates = append([]time.Time{start}, candidates...)
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Before(start) || (period == 0 && candidate.Equal(start) && seen > 0) {
+				continue
+			}
+			if r.Until != nil && candidate.After(*r.Until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}, false
+			}
+			if candidate.After(after) {
+				return candidate, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// expandPeriod lists the rule's candidate occurrences, in order, for the period that is
+// offset units (days, weeks, months or years) after the start's period.
+func (r RecurrenceRule) expandPeriod(start time.Time, offset int) []time.Time {
+	hour, minute, second := start.Clock()
+	loc := start.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	var candidates []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := start.AddDate(0, 0, offset)
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day.Weekday()) {
+			candidates = append(candidates, day)
+		}
+	case "WEEKLY":
+		// Weeks start on Monday (the RFC 5545 default WKST).
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*offset)
+		if len(r.ByDay) == 0 {
+			candidates = append(candidates, start.AddDate(0, 0, 7*offset))
+			break
+		}
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			for _, weekdayNum := range r.ByDay {
+				if weekdayNum.Weekday == day.Weekday() && r.matchesMonth(day.Month()) {
+					candidates = append(candidates, day)
+				}
+			}
+		}
+	case "MONTHLY":
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, offset, 0)
+		if r.matchesMonth(first.Month()) {
+			for _, day := range r.daysInMonth(first.Year(), first.Month(), start.Day()) {
+				candidates = append(candidates, at(first.Year(), first.Month(), day))
+			}
+		}
+	case "YEARLY":
+		year := start.Year() + offset
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(start.Month())}
+		}
+		for _, month := range months {
+			for _, day := range r.daysInMonth(year, time.Month(month), start.Day()) {
+				candidates = append(candidates, at(year, time.Month(month), day))
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return r.selectSetPos(candidates)
+}
+
+// selectSetPos keeps the period's candidates at the BYSETPOS positions, counting from 1, or
+// from the end when negative. Without BYSETPOS every candidate is kept.
+func (r RecurrenceRule) selectSetPos(candidates []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return candidates
+	}
+
+	selected := make(map[int]bool)
+	for _, position := range r.BySetPos {
+		index := position - 1
+		if position < 0 {
+			index = len(candidates) + position
+		}
+		if index >= 0 && index < len(candidates) {
+			selected[index] = true
+		}
+	}
+
+	var kept []time.Time
+	for i, candidate := range candidates {
+		if selected[i] {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
+
+// daysInMonth returns the days of a month selected by BYMONTHDAY or BYDAY, or the
+// default day (clamped to the month's length) when neither is set.
+func (r RecurrenceRule) daysInMonth(year int, month time.Month, defaultDay int) []int {
+	length := DaysInMonth(year, month)
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if defaultDay > length {
+			defaultDay = length
+		}
+		return []int{defaultDay}
+	}
+
+	selected := make(map[int]bool)
+	for _, day := range r.ByMonthDay {
+		if day < 0 {
+			day = length + day + 1
+		}
+		if day >= 1 && day <= length {
+			selected[day] = true
+		}
+	}
+
+	for _, weekdayNum := range r.ByDay {
+		var matches []int
+		for day := 1; day <= length; day++ {
+			if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() == weekdayNum.Weekday {
+				matches = append(matches, day)
+			}
+		}
+		switch {
+		case weekdayNum.Ordinal == 0:
+			for _, day := range matches {
+				selected[day] = true
+			}
+		case weekdayNum.Ordinal > 0 && weekdayNum.Ordinal <= len(matches):
+			selected[matches[weekdayNum.Ordinal-1]] = true
+		case weekdayNum.Ordinal < 0 && -weekdayNum.Ordinal <= len(matches):
+			selected[matches[len(matches)+weekdayNum.Ordinal]] = true
+		}
+	}
+
+	days := make([]int, 0, len(selected))
+	for day := range selected {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days
+}
+
+func (r RecurrenceRule) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RecurrenceRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := DaysInMonth(day.Year(), day.Month())
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || monthDay < 0 && length+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RecurrenceRule) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekdayNum := range r.ByDay {
+		if weekdayNum.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// DaysInMonth returns the number of days in the given month.
+func DaysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Describe renders the rule for users, e.g. "Repeats every 2 weeks until Dec 31, 2025".
+func (r RecurrenceRule) Describe() string {
+	units := map[string]string{"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year"}
+	adverbs := map[string]string{"DAILY": "daily", "WEEKLY": "weekly", "MONTHLY": "monthly", "YEARLY": "yearly"}
+
+	description := "Repeats " + adverbs[r.Freq]
+	if r.Interval > 1 {
+		description = fmt.Sprintf("Repeats every %d %ss", r.Interval, units[r.Freq])
+	}
+
+	switch {
+	case len(r.ByMonthDay) == 1 && r.ByMonthDay[0] == -1:
+		description += " on the last day"
+	case len(r.ByMonthDay) > 0:
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = ordinalWord(day)
+		}
+		description += " on the " + strings.Join(days, ", ")
+	case len(r.ByDay) > 0:
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			if day.Ordinal == 0 {
+				days[i] = day.Weekday.String()
+			} else {
+				days[i] = ordinalWord(day.Ordinal) + " " + day.Weekday.String()
+			}
+		}
+		description += " on " + strings.Join(days, ", ")
+		if r.ByDay[0].Ordinal != 0 {
+			description = strings.Replace(description, " on ", " on the ", 1)
+		}
+	}
+	if len(r.BySetPos) > 0 {
+		positions := make([]string, len(r.BySetPos))
+		for i, position := range r.BySetPos {
+			positions[i] = ordinalWord(position)
+		}
+		description += fmt.Sprintf(" (the %s of those each %s)", strings.Join(positions, ", "), units[r.Freq])
+	}
+
+	if r.Until != nil {
+		description += " until " + r.Until.Format("Jan 2, 2006")
+	}
+	if r.Count > 0 {
+		description += fmt.Sprintf(", %d times", r.Count)
+	}
+	return description
+}
+
+// ReminderRule returns the recurrence rule for a reminder from its stored RRULE, falling back
+// to its legacy frequency. recurring is false for one-time reminders.
+func ReminderRule(frequency string, rrule string) (rule RecurrenceRule, recurring bool, err error) {
+	if rrule != "" {
+		rule, err = ParseRRule(rrule)
+		return rule, err == nil, err
+	}
+	rule, recurring = FrequencyRRule(frequency)
+	return rule, recurring, nil
+}
+
+// DescribeSchedule renders a reminder's recurrence for users, e.g. "Repeats monthly on the last day".
+func DescribeSchedule(frequency string, rrule string) string {
+	rule, recurring, err := ReminderRule(frequency, rrule)
+	if err != nil || !recurring {
+		return "One-time"
+	}
+	return rule.Describe()
+}
+
+// FrequencyRRule converts a legacy frequency ("daily", "weekly", "monthly") into a rule.
+// ok is false for "once", an empty frequency or an unknown value.
+func FrequencyRRule(frequency string) (rule RecurrenceRule, ok bool) {
+	freq := map[string]string{"daily": "DAILY", "weekly": "WEEKLY", "monthly": "MONTHLY", "yearly": "YEARLY"}[frequency]
+	if freq == "" {
+		return RecurrenceRule{}, false
+	}
+	return RecurrenceRule{Freq: freq, Interval: 1}, true
+}
+
+// RuleFrequency returns the legacy frequency name stored alongside a rule, e.g. "monthly".
+func RuleFrequency(rule RecurrenceRule) string {
+	return strings.ToLower(rule.Freq)
+}
+
+func ordinalWord(n int) string {
+	if n == -1 {
+		return "last"
+	}
+	if n < 0 {
+		return fmt.Sprintf("%s to last", ordinalWord(-n))
+	}
+
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY: %s", value)
+	}
+
+	code := value[len(value)-2:]
+	weekday, ok := weekdayCodes[code]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday in BYDAY: %s", value)
+	}
+
+	weekdayNum := WeekdayNum{Weekday: weekday}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+		if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid ordinal in BYDAY: %s", value)
+		}
+		weekdayNum.Ordinal = ordinal
+	}
+	return weekdayNum, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	value = strings.TrimSuffix(value, "Z")
+	for _, layout := range []string{"20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				t = t.AddDate(0, 0, 1).Add(-time.Second) // A date-only UNTIL includes that whole day
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL: %s", value)
+}
+
+func parseIntList(value string, min int, max int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(part), "+"))
+		if err != nil || n < min || n > max {
+			return nil, fmt.Errorf("value out of range: %s", part)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string // Rule rendered back by String
+		wantErr string
+	}{
+		{value: "FREQ=MONTHLY", want: "FREQ=MONTHLY"},
+		{value: "RRULE:freq=weekly;interval=2", want: "FREQ=WEEKLY;INTERVAL=2"},
+		{value: "FREQ=MONTHLY;BYDAY=2FR;COUNT=12", want: "FREQ=MONTHLY;BYDAY=2FR;COUNT=12"},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{value: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", want: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+		{value: "FREQ=YEARLY;UNTIL=20251231", want: "FREQ=YEARLY;UNTIL=20251231T235959"},
+		{value: "FREQ=DAILY;BYDAY=MO", want: "FREQ=DAILY;BYDAY=MO"},
+		{value: "INTERVAL=2", wantErr: "missing FREQ"},
+		{value: "FREQ=HOURLY", wantErr: "unsupported frequency"},
+		{value: "FREQ=WEEKLY;INTERVAL=0", wantErr: "invalid interval"},
+		{value: "FREQ=MONTHLY;COUNT=3;UNTIL=20251231", wantErr: "both COUNT and UNTIL"},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: "invalid BYMONTHDAY"},
+		{value: "FREQ=MONTHLY;BYDAY=6FR", wantErr: "invalid ordinal"},
+		{value: "FREQ=DAILY;BYDAY=2MO", wantErr: "BYDAY ordinals"},
+		{value: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: "BYMONTHDAY cannot"},
+		{value: "FREQ=MONTHLY;BYSETPOS=1", wantErr: "BYSETPOS needs"},
+		{value: "FREQ=MONTHLY;BYSETPOS=0;BYDAY=MO", wantErr: "invalid BYSETPOS"},
+		{value: "FREQ=MONTHLY;WKST=SU", wantErr: "unsupported rule part"},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRRule(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRRule(%q) error = %v, want one containing %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRRule(%q) returned error: %v", tt.value, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("ParseRRule(%q).String() = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time // Every occurrence, or the first ones for endless rules
+	}{
+		{
+			name:  "monthly from the 31st keeps the end of the month",
+			rule:  "FREQ=MONTHLY",
+			start: date(2025, 1, 31),
+			want:  []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 31), date(2025, 4, 30)},
+		},
+		{
+			name:  "every 2 weeks",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: date(2025, 6, 2),
+			want:  []time.Time{date(2025, 6, 2), date(2025, 6, 16), date(2025, 6, 30)},
+		},
+		{
+			name:  "every 3 days",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: date(2025, 2, 26),
+			want:  []time.Time{date(2025, 2, 26), date(2025, 3, 1), date(2025, 3, 4)},
+		},
+		{
+			name:  "daily on Mondays only",
+			rule:  "FREQ=DAILY;BYDAY=MO",
+			start: date(2025, 6, 2),
+			want:  []time.Time{date(2025, 6, 2), date(2025, 6, 9), date(2025, 6, 16)},
+		},
+		{
+			name:  "daily on the 1st only",
+			rule:  "FREQ=DAILY;BYMONTHDAY=1",
+			start: date(2025, 6, 1),
+			want:  []time.Time{date(2025, 6, 1), date(2025, 7, 1), date(2025, 8, 1)},
+		},
+		{
+			name:  "second Friday",
+			rule:  "FREQ=MONTHLY;BYDAY=2FR",
+			start: date(2025, 6, 13),
+			want:  []time.Time{date(2025, 6, 13), date(2025, 7, 11), date(2025, 8, 8)},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2024, 1, 31),
+			want:  []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)},
+		},
+		{
+			name:  "last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: date(2025, 5, 30),
+			want:  []time.Time{date(2025, 5, 30), date(2025, 6, 30), date(2025, 7, 31), date(2025, 8, 29)},
+		},
+		{
+			name:  "first and last Monday",
+			rule:  "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1,-1",
+			start: date(2025, 6, 2),
+			want:  []time.Time{date(2025, 6, 2), date(2025, 6, 30), date(2025, 7, 7), date(2025, 7, 28)},
+		},
+		{
+			name:  "yearly on Feb 29 falls back in other years",
+			rule:  "FREQ=YEARLY",
+			start: date(2024, 2, 29),
+			want:  []time.Time{date(2024, 2, 29), date(2025, 2, 28), date(2026, 2, 28)},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: date(2025, 1, 15),
+			want:  []time.Time{date(2025, 1, 15), date(2025, 2, 15), date(2025, 3, 15)},
+		},
+		{
+			name:  "until",
+			rule:  "FREQ=WEEKLY;UNTIL=20250620",
+			start: date(2025, 6, 1),
+			want:  []time.Time{date(2025, 6, 1), date(2025, 6, 8), date(2025, 6, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRRule(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: ParseRRule(%q) returned error: %v", tt.name, tt.rule, err)
+		}
+
+		after := tt.start.Add(-time.Second)
+		for i, want := range tt.want {
+			got, ok := rule.Next(tt.start, after)
+			if !ok {
+				t.Errorf("%s: occurrence %d: series ended, want %v", tt.name, i+1, want)
+				break
+			}
+			if !got.Equal(want) {
+				t.Errorf("%s: occurrence %d = %v, want %v", tt.name, i+1, got, want)
+				break
+			}
+			after = got
+		}
+
+		if rule.Count > 0 || rule.Until != nil {
+			if got, ok := rule.Next(tt.start, after); ok {
+				t.Errorf("%s: got %v after the series should have ended", tt.name, got)
+			}
+		}
+	}
+}
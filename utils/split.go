@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SplitPerson is someone named in a split, with the share they owe if it was given.
+type SplitPerson struct {
+	Name   string
+	Amount *float64 // nil for an equal share of what's left
+}
+
+// SplitShare is the amount one person owes for a split expense.
+type SplitShare struct {
+	Name   string
+	Amount float64
+}
+
+var splitMessagePattern = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d{1,2})?)\s+for\s+(.+?)\s+split\s+with\s+(.+?)\s*$`)
+var splitPersonPattern = regexp.MustCompile(`^(.+?)(?:\s+(\d+(?:\.\d{1,2})?))?$`)
+var splitSeparatorPattern = regexp.MustCompile(`(?i)\s*,\s*(?:and\s+)?|\s+and\s+`)
+
+// GetSplitExpenseDataFromMessage parses "[amount] for [item] split with [name], [name]", where
+// each name may be followed by a custom share, e.g. "1200 for lunch split with ana 500, ben".
+func GetSplitExpenseDataFromMessage(message string) (amount float64, category string, people []SplitPerson, err error) {
+	matches := splitMessagePattern.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: [amount] for [item] split with [name], [name]")
+		return
+	}
+
+	amount, err = strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		err = fmt.Errorf("invalid amount format")
+		return
+	}
+	category = strings.TrimSpace(matches[2])
+
+	seen := make(map[string]bool)
+	for _, part := range splitSeparatorPattern.Split(matches[3], -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		personMatches := splitPersonPattern.FindStringSubmatch(part)
+		person := SplitPerson{Name: strings.TrimSpace(personMatches[1])}
+		if personMatches[2] != "" {
+			share, parseErr := strconv.ParseFloat(personMatches[2], 64)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid share for %s", person.Name)
+				return
+			}
+			person.Amount = &share
+		}
+		if seen[strings.ToLower(person.Name)] {
+			err = fmt.Errorf("%s is named more than once", person.Name)
+			return
+		}
+		seen[strings.ToLower(person.Name)] = true
+		people = append(people, person)
+	}
+
+	if len(people) == 0 {
+		err = fmt.Errorf("no one to split with")
+	}
+	return
+}
+
+// ComputeSplitShares works out what each person owes for a split expense. People with a custom
+// share owe exactly that; the rest of the total is divided equally between the payer and
+// everyone without one. Shares are rounded to the centavo and the payer absorbs the remainder.
+func ComputeSplitShares(total float64, people []SplitPerson) (payerShare float64, shares []SplitShare, err error) {
+	if total <= 0 {
+		return 0, nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	remaining := int64(math.Round(total * 100))
+	equalParts := int64(1) // The payer always takes part in the equal split
+	for _, person := range people {
+		if person.Amount == nil {
+			equalParts++
+			continue
+		}
+		if *person.Amount <= 0 {
+			return 0, nil, fmt.Errorf("%s's share must be greater than zero", person.Name)
+		}
+		remaining -= int64(math.Round(*person.Amount * 100))
+	}
+	if remaining < 0 {
+		return 0, nil, fmt.Errorf("the shares add up to more than ₱%.2f", total)
+	}
+
+	equalShare := remaining / equalParts
+	payerCents := remaining
+	for _, person := range people {
+		cents := equalShare
+		if person.Amount != nil {
+			cents = int64(math.Round(*person.Amount * 100))
+		} else {
+			payerCents -= equalShare
+		}
+		shares = append(shares, SplitShare{Name: person.Name, Amount: float64(cents) / 100})
+	}
+	return float64(payerCents) / 100, shares, nil
+}
+
+// GetSettleDataFromMessage parses "settle [name] [amount]"; amount is nil to settle in full.
+func GetSettleDataFromMessage(message string) (name string, amount *float64, err error) {
+	re := regexp.MustCompile(`(?i)^\s*settle\s+(.+?)(?:\s+(\d+(?:\.\d{1,2})?))?\s*$`)
+	matches := re.FindStringSubmatch(message)
+	if matches == nil {
+		err = fmt.Errorf("invalid format, expected: settle [name] [amount]")
+		return
+	}
+
+	name = matches[1]
+	if matches[2] != "" {
+		value, parseErr := strconv.ParseFloat(matches[2], 64)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid amount format")
+			return
+		}
+		amount = &value
+	}
+	return
+}
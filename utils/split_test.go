@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetSplitExpenseDataFromMessage(t *testing.T) {
+	share := func(amount float64) *float64 { return &amount }
+	tests := []struct {
+		message      string
+		wantAmount   float64
+		wantCategory string
+		wantPeople   []SplitPerson
+		wantErr      string
+	}{
+		{
+			message:      "1200 for lunch split with ana, ben",
+			wantAmount:   1200,
+			wantCategory: "lunch",
+			wantPeople:   []SplitPerson{{Name: "ana"}, {Name: "ben"}},
+		},
+		{
+			message:      "1500.50 FOR GROCERIES SPLIT WITH ANA 500, BEN AND CARLO",
+			wantAmount:   1500.50,
+			wantCategory: "GROCERIES",
+			wantPeople:   []SplitPerson{{Name: "ANA", Amount: share(500)}, {Name: "BEN"}, {Name: "CARLO"}},
+		},
+		{
+			message:      "900 for team dinner split with ana marie 300.25, and ben",
+			wantAmount:   900,
+			wantCategory: "team dinner",
+			wantPeople:   []SplitPerson{{Name: "ana marie", Amount: share(300.25)}, {Name: "ben"}},
+		},
+		{message: "1200 for lunch", wantErr: "invalid format"},
+		{message: "lunch split with ana", wantErr: "invalid format"},
+		{message: "1200 for lunch split with ana, ANA", wantErr: "named more than once"},
+	}
+
+	for _, tt := range tests {
+		amount, category, people, err := GetSplitExpenseDataFromMessage(tt.message)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetSplitExpenseDataFromMessage(%q) error = %v, want one containing %q", tt.message, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetSplitExpenseDataFromMessage(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		if amount != tt.wantAmount || category != tt.wantCategory || !reflect.DeepEqual(people, tt.wantPeople) {
+			t.Errorf("GetSplitExpenseDataFromMessage(%q) = %v, %q, %+v, want %v, %q, %+v", tt.message, amount, category, people, tt.wantAmount, tt.wantCategory, tt.wantPeople)
+		}
+	}
+}
+
+func TestComputeSplitShares(t *testing.T) {
+	share := func(amount float64) *float64 { return &amount }
+	tests := []struct {
+		name       string
+		total      float64
+		people     []SplitPerson
+		wantPayer  float64
+		wantShares []SplitShare
+		wantErr    string
+	}{
+		{
+			name:       "equal split",
+			total:      1200,
+			people:     []SplitPerson{{Name: "ana"}, {Name: "ben"}},
+			wantPayer:  400,
+			wantShares: []SplitShare{{Name: "ana", Amount: 400}, {Name: "ben", Amount: 400}},
+		},
+		{
+			name:       "payer absorbs the centavo remainder",
+			total:      100,
+			people:     []SplitPerson{{Name: "ana"}, {Name: "ben"}},
+			wantPayer:  33.34,
+			wantShares: []SplitShare{{Name: "ana", Amount: 33.33}, {Name: "ben", Amount: 33.33}},
+		},
+		{
+			name:       "custom share with the rest split equally",
+			total:      1200,
+			people:     []SplitPerson{{Name: "ana", Amount: share(500)}, {Name: "ben"}},
+			wantPayer:  350,
+			wantShares: []SplitShare{{Name: "ana", Amount: 500}, {Name: "ben", Amount: 350}},
+		},
+		{
+			name:       "others cover the whole amount",
+			total:      1000,
+			people:     []SplitPerson{{Name: "ana", Amount: share(600)}, {Name: "ben", Amount: share(400)}},
+			wantPayer:  0,
+			wantShares: []SplitShare{{Name: "ana", Amount: 600}, {Name: "ben", Amount: 400}},
+		},
+		{name: "zero total", total: 0, people: []SplitPerson{{Name: "ana"}}, wantErr: "greater than zero"},
+		{name: "zero share", total: 100, people: []SplitPerson{{Name: "ana", Amount: share(0)}}, wantErr: "ana's share"},
+		{name: "shares over the total", total: 100, people: []SplitPerson{{Name: "ana", Amount: share(60)}, {Name: "ben", Amount: share(50)}}, wantErr: "add up to more"},
+	}
+
+	for _, tt := range tests {
+		payer, shares, err := ComputeSplitShares(tt.total, tt.people)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: returned error: %v", tt.name, err)
+			continue
+		}
+		if payer != tt.wantPayer || !reflect.DeepEqual(shares, tt.wantShares) {
+			t.Errorf("%s: got %v, %+v, want %v, %+v", tt.name, payer, shares, tt.wantPayer, tt.wantShares)
+		}
+	}
+}
+
+func TestGetSettleDataFromMessage(t *testing.T) {
+	tests := []struct {
+		message    string
+		wantName   string
+		wantAmount float64 // 0 for a full settle-up
+		wantErr    bool
+	}{
+		{message: "settle ana 200", wantName: "ana", wantAmount: 200},
+		{message: "SETTLE ANA MARIE 150.75", wantName: "ANA MARIE", wantAmount: 150.75},
+		{message: "settle ana", wantName: "ana"},
+		{message: "settle", wantErr: true},
+		{message: "pay ana 200", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name, amount, err := GetSettleDataFromMessage(tt.message)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GetSettleDataFromMessage(%q) returned no error", tt.message)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetSettleDataFromMessage(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		var got float64
+		if amount != nil {
+			got = *amount
+		}
+		if name != tt.wantName || got != tt.wantAmount || (amount == nil) != (tt.wantAmount == 0) {
+			t.Errorf("GetSettleDataFromMessage(%q) = %q, %v, want %q, %v", tt.message, name, got, tt.wantName, tt.wantAmount)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// MinBaselineSamples is the number of past expenses a category needs before
+// its baseline is trusted for unusual expense detection.
+const MinBaselineSamples = 5
+
+// CategoryBaseline summarizes a user's recent spending in a single category.
+type CategoryBaseline struct {
+	Median  float64
+	Spread  float64
+	Samples int
+}
+
+// sensitivityThresholds maps a user's alert sensitivity to how many spreads
+// away from the median an amount may be before it is flagged.
+var sensitivityThresholds = map[string]float64{
+	"low":    6.0,
+	"medium": 4.0,
+	"high":   2.5,
+}
+
+// IsValidSensitivity reports whether level is a known alert sensitivity, including "off".
+func IsValidSensitivity(level string) bool {
+	if level == "off" {
+		return true
+	}
+	_, ok := sensitivityThresholds[level]
+	return ok
+}
+
+// Median returns the median of values. It returns 0 for an empty slice.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// ComputeBaseline builds a category baseline from past amounts using the median
+// and the median absolute deviation (scaled to be comparable to a standard deviation).
+// The spread never drops below 10% of the median so that a run of identical
+// amounts does not flag every small change.
+func ComputeBaseline(amounts []float64) CategoryBaseline {
+	baseline := CategoryBaseline{Samples: len(amounts)}
+	if len(amounts) == 0 {
+		return baseline
+	}
+
+	baseline.Median = Median(amounts)
+
+	deviations := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		deviations[i] = math.Abs(amount - baseline.Median)
+	}
+	baseline.Spread = Median(deviations) * 1.4826
+
+	if minSpread := baseline.Median * 0.1; baseline.Spread < minSpread {
+		baseline.Spread = minSpread
+	}
+
+	return baseline
+}
+
+// IsUnusualAmount reports whether amount is far outside the baseline for the
+// given sensitivity. Baselines with too little history are never flagged.
+func IsUnusualAmount(amount float64, baseline CategoryBaseline, sensitivity string) bool {
+	threshold, ok := sensitivityThresholds[sensitivity]
+	if !ok {
+		return false // "off" or unknown sensitivity disables detection
+	}
+
+	if baseline.Samples < MinBaselineSamples || baseline.Spread <= 0 {
+		return false
+	}
+
+	return math.Abs(amount-baseline.Median)/baseline.Spread > threshold
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeBaseline(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []float64
+		want    CategoryBaseline
+	}{
+		{name: "empty history", amounts: nil, want: CategoryBaseline{}},
+		{name: "single amount uses the minimum spread", amounts: []float64{200}, want: CategoryBaseline{Median: 200, Spread: 20, Samples: 1}},
+		{name: "identical amounts use the minimum spread", amounts: []float64{100, 100, 100, 100, 100}, want: CategoryBaseline{Median: 100, Spread: 10, Samples: 5}},
+		{name: "zero amounts have no spread", amounts: []float64{0, 0, 0}, want: CategoryBaseline{Samples: 3}},
+		{name: "scaled median absolute deviation", amounts: []float64{500, 100, 300, 200, 400}, want: CategoryBaseline{Median: 300, Spread: 148.26, Samples: 5}},
+		{name: "even count averages the middle amounts", amounts: []float64{100, 200}, want: CategoryBaseline{Median: 150, Spread: 74.13, Samples: 2}},
+		{name: "outlier doesn't inflate the spread", amounts: []float64{100, 110, 90, 105, 5000}, want: CategoryBaseline{Median: 105, Spread: 10.5, Samples: 5}},
+	}
+
+	for _, tt := range tests {
+		got := ComputeBaseline(tt.amounts)
+		if got.Samples != tt.want.Samples || math.Abs(got.Median-tt.want.Median) > 1e-9 || math.Abs(got.Spread-tt.want.Spread) > 1e-9 {
+			t.Errorf("%s: ComputeBaseline(%v) = %+v, want %+v", tt.name, tt.amounts, got, tt.want)
+		}
+	}
+}
+
+func TestIsUnusualAmount(t *testing.T) {
+	baseline := CategoryBaseline{Median: 100, Spread: 10, Samples: MinBaselineSamples}
+	tests := []struct {
+		name        string
+		amount      float64
+		baseline    CategoryBaseline
+		sensitivity string
+		want        bool
+	}{
+		{name: "3 spreads on high", amount: 130, baseline: baseline, sensitivity: "high", want: true},
+		{name: "3 spreads on medium", amount: 130, baseline: baseline, sensitivity: "medium", want: false},
+		{name: "3 spreads on low", amount: 130, baseline: baseline, sensitivity: "low", want: false},
+		{name: "5 spreads on medium", amount: 150, baseline: baseline, sensitivity: "medium", want: true},
+		{name: "5 spreads on low", amount: 150, baseline: baseline, sensitivity: "low", want: false},
+		{name: "7 spreads on low", amount: 170, baseline: baseline, sensitivity: "low", want: true},
+		{name: "exactly at the medium threshold", amount: 140, baseline: baseline, sensitivity: "medium", want: false},
+		{name: "far below the median", amount: 40, baseline: baseline, sensitivity: "medium", want: true},
+		{name: "off", amount: 1000, baseline: baseline, sensitivity: "off", want: false},
+		{name: "unknown sensitivity", amount: 1000, baseline: baseline, sensitivity: "extreme", want: false},
+		{name: "too little history", amount: 1000, baseline: CategoryBaseline{Median: 100, Spread: 10, Samples: MinBaselineSamples - 1}, sensitivity: "high", want: false},
+		{name: "zero spread", amount: 1000, baseline: CategoryBaseline{Samples: MinBaselineSamples}, sensitivity: "high", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsUnusualAmount(tt.amount, tt.baseline, tt.sensitivity); got != tt.want {
+			t.Errorf("%s: IsUnusualAmount(%v, %+v, %q) = %v, want %v", tt.name, tt.amount, tt.baseline, tt.sensitivity, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidSensitivity(t *testing.T) {
+	for _, level := range []string{"off", "low", "medium", "high"} {
+		if !IsValidSensitivity(level) {
+			t.Errorf("IsValidSensitivity(%q) = false, want true", level)
+		}
+	}
+	for _, level := range []string{"", "HIGH", "extreme"} {
+		if IsValidSensitivity(level) {
+			t.Errorf("IsValidSensitivity(%q) = true, want false", level)
+		}
+	}
+}
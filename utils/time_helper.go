@@ -2,29 +2,142 @@ package utils
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// CalculateNextDueDate calculates the next due date based on the current due date and frequency.
-// It resets the time to the beginning of the day (00:00:00).
-func CalculateNextDueDate(currentDueDate time.Time, frequency string) (time.Time, error) {
-	// Reset currentDueDate to the beginning of its day to ensure consistent calculations
-	currentDate := time.Date(currentDueDate.Year(), currentDueDate.Month(), currentDueDate.Day(), 0, 0, 0, 0, currentDueDate.Location())
-
-	switch frequency {
-	case "daily":
-		return currentDate.AddDate(0, 0, 1), nil
-	case "weekly":
-		return currentDate.AddDate(0, 0, 7), nil
-	case "monthly":
-		return currentDate.AddDate(0, 1, 0), nil
-	case "once":
-		// For "once" frequency, it implies no next due date from recurrence.
-		// However, this function is typically called for rescheduling.
-		// Returning an error or a zero time might be appropriate.
-		// For now, let's return an error as it's unexpected in a rescheduling context.
-		return time.Time{}, fmt.Errorf("cannot calculate next due date for 'once' frequency")
-	default:
-		return time.Time{}, fmt.Errorf("unknown frequency: %s", frequency)
+// ParseTimeOfDay parses times such as "9am", "9:30 pm", "21:00" or "9" into an hour and minute.
+func ParseTimeOfDay(value string) (hour int, minute int, err error) {
+	re := regexp.MustCompile(`(?i)^\s*(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s*$`)
+	matches := re.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, 0, fmt.Errorf("invalid time format: %s", value)
 	}
+
+	hour, _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		minute, _ = strconv.Atoi(matches[2])
+	}
+
+	switch strings.ToLower(matches[3]) {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid hour: %s", value)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid hour: %s", value)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time: %s", value)
+	}
+	return hour, minute, nil
+}
+
+// ReminderDueAt returns the moment a reminder is due. DueDate holds the wall-clock date (and time,
+// when dueTimeSet) in the user's timezone; reminders without their own time use defaultTime ("15:04").
+func ReminderDueAt(dueDate time.Time, dueTimeSet bool, defaultTime string, loc *time.Location) time.Time {
+	hour, minute := dueDate.Hour(), dueDate.Minute()
+	if !dueTimeSet {
+		var err error
+		hour, minute, err = ParseTimeOfDay(defaultTime)
+		if err != nil {
+			hour, minute = 0, 0 // Fall back to midnight, the original behaviour
+		}
+	}
+	return time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), hour, minute, 0, 0, loc)
+}
+
+// FormatDueDate renders a reminder's due date, including the time when one was set.
+func FormatDueDate(dueDate time.Time, dueTimeSet bool) string {
+	if dueTimeSet {
+		return dueDate.Format("2006-01-02 3:04 PM")
+	}
+	return dueDate.Format("2006-01-02")
+}
+
+// ParseLeadDays parses a comma-separated list of advance-notice days such as "3,1".
+// Duplicates and zero are dropped (the due-day notice is always sent) and the result is sorted ascending.
+func ParseLeadDays(value string) ([]int, error) {
+	seen := make(map[int]bool)
+	var days []int
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		day, err := strconv.Atoi(part)
+		if err != nil || day < 0 {
+			return nil, fmt.Errorf("invalid lead time: %s", part)
+		}
+		if day == 0 || seen[day] {
+			continue
+		}
+		seen[day] = true
+		days = append(days, day)
+	}
+
+	sort.Ints(days)
+	return days, nil
+}
+
+// FormatLeadDays renders lead days for display, e.g. "3 and 1 day(s) before".
+func FormatLeadDays(days []int) string {
+	if len(days) == 0 {
+		return "on the due date only"
+	}
+
+	parts := make([]string, len(days))
+	for i := range days {
+		parts[i] = strconv.Itoa(days[len(days)-1-i])
+	}
+	return strings.Join(parts, ", ") + " day(s) before and on the due date"
+}
+
+// DaysUntil returns the number of calendar days from now until dueAt, compared at midnight
+// in dueAt's location. It is negative when the due date has passed.
+func DaysUntil(dueAt time.Time, now time.Time) int {
+	now = now.In(dueAt.Location())
+	due := time.Date(dueAt.Year(), dueAt.Month(), dueAt.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(due.Sub(today).Hours() / 24)
+}
+
+// LeadStageAt returns the advance-notice stage that is current at now for a reminder due at dueAt.
+// Each stage fires its lead time in days before dueAt; the current stage is the most recent one
+// to have fired. ok is false when no stage has fired yet or the reminder is already due.
+func LeadStageAt(dueAt time.Time, now time.Time, leadDays []int) (stage int, ok bool) {
+	if !now.Before(dueAt) {
+		return 0, false
+	}
+	for _, day := range leadDays {
+		if !now.Before(dueAt.AddDate(0, 0, -day)) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// Overdue reminders are nudged daily for the first OverdueDailyEscalations days, then weekly.
+const OverdueDailyEscalations = 3
+
+// NextEscalationAt returns when the next overdue nudge is due for a reminder that has already
+// received sent nudges.
+func NextEscalationAt(dueAt time.Time, sent int) time.Time {
+	if sent < OverdueDailyEscalations {
+		return dueAt.AddDate(0, 0, sent+1)
+	}
+	return dueAt.AddDate(0, 0, OverdueDailyEscalations+7*(sent-OverdueDailyEscalations+1))
 }
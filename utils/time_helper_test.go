@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLeadDays(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int
+		wantErr bool
+	}{
+		{value: "3,1", want: []int{1, 3}},
+		{value: " 7 , 3,3, 0 ", want: []int{3, 7}},
+		{value: "1,,2", want: []int{1, 2}},
+		{value: "0", want: nil},
+		{value: "", want: nil},
+		{value: "-1", wantErr: true},
+		{value: "3,one", wantErr: true},
+		{value: "1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLeadDays(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLeadDays(%q) returned no error", tt.value)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLeadDays(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestLeadStageAt(t *testing.T) {
+	dueAt := time.Date(2025, time.May, 10, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		now       time.Time
+		leadDays  []int
+		wantStage int
+		wantOK    bool
+	}{
+		{name: "before any stage", now: time.Date(2025, time.May, 6, 9, 0, 0, 0, time.UTC), leadDays: []int{1, 3}},
+		{name: "just before the 3-day stage", now: time.Date(2025, time.May, 7, 8, 59, 0, 0, time.UTC), leadDays: []int{1, 3}},
+		{name: "3-day stage fires", now: time.Date(2025, time.May, 7, 9, 0, 0, 0, time.UTC), leadDays: []int{1, 3}, wantStage: 3, wantOK: true},
+		{name: "between stages", now: time.Date(2025, time.May, 8, 18, 0, 0, 0, time.UTC), leadDays: []int{1, 3}, wantStage: 3, wantOK: true},
+		{name: "1-day stage replaces the 3-day one", now: time.Date(2025, time.May, 9, 10, 0, 0, 0, time.UTC), leadDays: []int{1, 3}, wantStage: 1, wantOK: true},
+		{name: "due", now: dueAt, leadDays: []int{1, 3}},
+		{name: "overdue", now: dueAt.Add(time.Hour), leadDays: []int{1, 3}},
+		{name: "due-day notice only", now: time.Date(2025, time.May, 9, 10, 0, 0, 0, time.UTC), leadDays: nil},
+	}
+
+	for _, tt := range tests {
+		stage, ok := LeadStageAt(dueAt, tt.now, tt.leadDays)
+		if stage != tt.wantStage || ok != tt.wantOK {
+			t.Errorf("%s: LeadStageAt(%v, %v, %v) = %d, %v, want %d, %v", tt.name, dueAt, tt.now, tt.leadDays, stage, ok, tt.wantStage, tt.wantOK)
+		}
+	}
+}
+
+func TestFormatLeadDays(t *testing.T) {
+	tests := []struct {
+		days []int
+		want string
+	}{
+		{days: nil, want: "on the due date only"},
+		{days: []int{1}, want: "1 day(s) before and on the due date"},
+		{days: []int{1, 3, 7}, want: "7, 3, 1 day(s) before and on the due date"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatLeadDays(tt.days); got != tt.want {
+			t.Errorf("FormatLeadDays(%v) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}